@@ -0,0 +1,146 @@
+package nextcloud
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// capabilitiesCacheKey is the connection cache key of the parsed capabilities
+const capabilitiesCacheKey = "nextcloud_capabilities"
+
+// capabilitiesCacheTTL is how long the capabilities are reused before being fetched again
+const capabilitiesCacheTTL = 5 * time.Minute
+
+// serverVersion is the version block of the capabilities response
+type serverVersion struct {
+	Major   int    `json:"major"`
+	Minor   int    `json:"minor"`
+	Micro   int    `json:"micro"`
+	String  string `json:"string"`
+	Edition string `json:"edition"`
+}
+
+// AtLeast reports whether the server version is major.minor or newer
+func (v serverVersion) AtLeast(major, minor int) bool {
+	if v.Major != major {
+		return v.Major > major
+	}
+	return v.Minor >= minor
+}
+
+// ncCapabilities is the data member of the capabilities response. Each
+// enabled app exposing capabilities has its own key in Capabilities.
+type ncCapabilities struct {
+	Version      serverVersion          `json:"version"`
+	Capabilities map[string]interface{} `json:"capabilities"`
+}
+
+// HasApp reports whether the app published capabilities, i.e. is enabled
+func (c *ncCapabilities) HasApp(app string) bool {
+	_, ok := c.Capabilities[app]
+	return ok
+}
+
+// Lookup returns the capability at path (app key, then nested keys)
+func (c *ncCapabilities) Lookup(path ...string) (interface{}, bool) {
+	var value interface{} = c.Capabilities
+	for _, key := range path {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = m[key]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// Bool returns the boolean capability at path, false when it is absent. Like
+// the rest of the OCS API, capabilities may hold booleans as 0/1 or strings.
+func (c *ncCapabilities) Bool(path ...string) bool {
+	value, _ := c.Lookup(path...)
+	switch v := value.(type) {
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		return v == "1" || v == "true" || v == "yes"
+	}
+	return false
+}
+
+// Int returns the numeric capability at path, 0 when it is absent or not a number
+func (c *ncCapabilities) Int(path ...string) int64 {
+	value, _ := c.Lookup(path...)
+	switch v := value.(type) {
+	case float64:
+		return int64(v)
+	case string:
+		n, _ := strconv.ParseInt(v, 10, 64)
+		return n
+	}
+	return 0
+}
+
+// getCapabilities returns the server capabilities, fetched once and then kept
+// in the connection cache for capabilitiesCacheTTL. Past that, they are
+// revalidated with a conditional request.
+func getCapabilities(ctx context.Context, d *plugin.QueryData) (*ncCapabilities, error) {
+	if cached, ok := d.ConnectionCache.Get(ctx, capabilitiesCacheKey); ok {
+		return cached.(*ncCapabilities), nil
+	}
+
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	caps, err := ocsGetDataConditional[ncCapabilities](ctx, d, client, "ocs/v1.php/cloud/capabilities?format=json")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := d.ConnectionCache.SetWithTTL(ctx, capabilitiesCacheKey, &caps, capabilitiesCacheTTL); err != nil {
+		plugin.Logger(ctx).Warn("getCapabilities", "cache_error", err)
+	}
+	return &caps, nil
+}
+
+// appCapabilityKeys lists the apps known to publish a capabilities entry, so
+// that their absence from the capabilities means the app is disabled.
+var appCapabilityKeys = map[string]string{
+	"activity":       "activity",
+	"circles":        "circles",
+	"notes":          "notes",
+	"notifications":  "notifications",
+	"user_status":    "user_status",
+	"weather_status": "weather_status",
+}
+
+// listIfAppEnabled wraps the list hydrate of a table backed by an optional app.
+// When the app is disabled (per the capabilities, or because its routes answer
+// 404) the table returns no rows and a warning is logged instead of an error.
+func listIfAppEnabled(app string, hydrate plugin.HydrateFunc) plugin.HydrateFunc {
+	return func(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+		if key, ok := appCapabilityKeys[app]; ok {
+			caps, err := getCapabilities(ctx, d)
+			if err == nil && !caps.HasApp(key) {
+				plugin.Logger(ctx).Warn("listIfAppEnabled", "app", app, "table", d.Table.Name, "message", "app not enabled, returning no rows")
+				return nil, nil
+			}
+		}
+
+		result, err := hydrate(ctx, d, h)
+		var disabled *appDisabledError
+		if errors.As(err, &disabled) {
+			plugin.Logger(ctx).Warn("listIfAppEnabled", "app", app, "table", d.Table.Name, "error", err)
+			return nil, nil
+		}
+		return result, err
+	}
+}
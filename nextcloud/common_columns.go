@@ -0,0 +1,46 @@
+package nextcloud
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// commonColumns appends the columns shared by every table, which identify the
+// server a row comes from when several connections are aggregated
+func commonColumns(columns []*plugin.Column) []*plugin.Column {
+	return append(columns, &plugin.Column{
+		Name:        "_nextcloud_server",
+		Type:        proto.ColumnType_STRING,
+		Description: "URL of the Nextcloud server the row comes from (server_url of the connection)",
+		Hydrate:     getNextcloudServer,
+		Transform:   transform.FromValue(),
+	})
+}
+
+// getNextcloudServer returns the normalized server_url of the connection,
+// without any request to the server
+func getNextcloudServer(_ context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	serverURL := configOrEnv(GetConfig(d.Connection).ServerURL, "NEXTCLOUD_URL")
+	if serverURL == "" {
+		return nil, nil
+	}
+	return normalizeServerURL(serverURL)
+}
+
+// onlyColumnsRequested reports whether the query selects no column besides the
+// given "free" ones (and the common columns), so that list hydrates can skip
+// the requests filling the expensive ones
+func onlyColumnsRequested(d *plugin.QueryData, free ...string) bool {
+	for _, column := range d.QueryContext.Columns {
+		if strings.HasPrefix(column, "_") || slices.Contains(free, column) {
+			continue
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,82 @@
+package nextcloud
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// conditionalCacheTTL is how long a response is kept for revalidation with If-None-Match
+const conditionalCacheTTL = time.Hour
+
+// conditionalResponse is a response body kept in the connection cache with its ETag
+type conditionalResponse struct {
+	ETag        string
+	ContentType string
+	Body        []byte
+}
+
+// ocsGetDataConditional works like ocsGetData for endpoints whose data rarely
+// changes: the last response is kept in the connection cache with its ETag,
+// and reused when the server answers 304 Not Modified to If-None-Match.
+// Endpoints that do not send an ETag are simply fetched every time.
+func ocsGetDataConditional[D any](ctx context.Context, d *plugin.QueryData, client *NextcloudClient, endpoint string) (D, error) {
+	cacheKey := "nextcloud_conditional:" + endpoint
+
+	var cached *conditionalResponse
+	if value, ok := d.ConnectionCache.Get(ctx, cacheKey); ok {
+		cached = value.(*conditionalResponse)
+	}
+	etag := ""
+	if cached != nil {
+		etag = cached.ETag
+	}
+
+	resp, err := client.MakeConditionalRequest(ctx, endpoint, etag)
+	if err != nil {
+		var zero D
+		return zero, ocsErrorFromHTTP(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		return ocsDecode[D](cached.response(), endpoint)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		var zero D
+		return zero, err
+	}
+	fresh := &conditionalResponse{
+		ETag:        resp.Header.Get("ETag"),
+		ContentType: resp.Header.Get("Content-Type"),
+		Body:        body,
+	}
+	data, err := ocsDecode[D](fresh.response(), endpoint)
+	if err != nil {
+		return data, err
+	}
+
+	if fresh.ETag != "" {
+		if err := d.ConnectionCache.SetWithTTL(ctx, cacheKey, fresh, conditionalCacheTTL); err != nil {
+			plugin.Logger(ctx).Warn("ocsGetDataConditional", "cache_error", err)
+		}
+	}
+	return data, nil
+}
+
+// response rebuilds an *http.Response over the cached body, as ocsDecode expects
+func (r *conditionalResponse) response() *http.Response {
+	header := http.Header{}
+	header.Set("Content-Type", r.ContentType)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     header,
+		Body:       io.NopCloser(bytes.NewReader(r.Body)),
+	}
+}
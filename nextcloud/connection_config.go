@@ -1,15 +1,22 @@
 package nextcloud
 
 import (
+	"bufio"
+	"compress/gzip"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"os"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/turbot/steampipe-plugin-sdk/v5/connection"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
 )
 
@@ -18,6 +25,59 @@ type NextcloudConfig struct {
 	ServerURL *string `cty:"server_url"`
 	Username  *string `cty:"username"`
 	Password  *string `cty:"password"`
+
+	// Jeton optionnel du monitoring serverinfo, envoyé dans l’en-tête NC-Token
+	ServerinfoToken *string `cty:"serverinfo_token"`
+
+	// Proxy HTTP/SOCKS optionnel (sinon HTTP_PROXY/HTTPS_PROXY/NO_PROXY)
+	ProxyURL *string `cty:"proxy_url"`
+
+	// Certificat client (mTLS) : chemins de fichiers PEM ou PEM en ligne
+	ClientCert *string `cty:"client_cert"`
+	ClientKey  *string `cty:"client_key"`
+
+	// Version minimale de TLS : "1.2" ou "1.3" (par défaut celle de Go)
+	TLSMinVersion *string `cty:"tls_min_version"`
+
+	// Langue des sujets d’activité et des notifications (en-tête Accept-Language)
+	Language *string `cty:"language"`
+
+	// Journalisation des requêtes et réponses HTTP (sans les identifiants)
+	Debug *bool `cty:"debug"`
+
+	// Ne pas vérifier la connexion (endpoint capabilities) à la création du client
+	SkipConnectionTest *bool `cty:"skip_connection_test"`
+
+	// Version des endpoints OCS : "v1", "v2" ou "auto" (par défaut)
+	OCSVersion *string `cty:"ocs_version"`
+
+	// Nombre maximal de requêtes de détail lancées en parallèle (10 par défaut)
+	MaxConcurrency *int `cty:"max_concurrency"`
+
+	// Nouvelles tentatives (erreurs réseau, 429, 502-504) : délai de base,
+	// délai maximal (en ms) et nombre de tentatives
+	RetryBaseMS *int `cty:"retry_base_ms"`
+	RetryMaxMS  *int `cty:"retry_max_ms"`
+	MaxRetries  *int `cty:"max_retries"`
+
+	// Taille maximale lue d’une réponse (100 Mo par défaut)
+	MaxResponseBytes *int64 `cty:"max_response_bytes"`
+
+	// En-tête User-Agent envoyé à la place de celui par défaut
+	UserAgent *string `cty:"user_agent"`
+
+	// Durée de rétention de la corbeille en jours (trashbin_retention_obligation
+	// du serveur, non exposé par l’API) : 30 par défaut, 0 pour illimitée
+	TrashbinRetentionDays *int `cty:"trashbin_retention_days"`
+}
+
+// pluginVersion est la version du plugin, annoncée dans le User-Agent par
+// défaut (remplaçable à la compilation avec -ldflags "-X ...nextcloud.pluginVersion=x.y.z")
+var pluginVersion = "1.0"
+
+// defaultUserAgent est le User-Agent envoyé quand user_agent n’est pas renseigné
+func defaultUserAgent() string {
+	return "Steampipe-Nextcloud-Plugin/" + pluginVersion
 }
 
 // NextcloudClient est un client HTTP pour l’API OCS de Nextcloud.
@@ -26,6 +86,41 @@ type NextcloudClient struct {
 	Username   string
 	Password   string
 	HTTPClient *http.Client
+
+	// ServerinfoToken est envoyé en NC-Token sur les endpoints serverinfo uniquement
+	ServerinfoToken string
+
+	// UserAgent est envoyé dans l’en-tête User-Agent de chaque requête
+	UserAgent string
+
+	// Language est envoyé en Accept-Language s’il est renseigné (sinon le serveur décide)
+	Language string
+
+	// Debug active la journalisation de chaque requête (méthode, URL, statut, début du corps)
+	Debug bool
+
+	// ServerVersion est renseignée par TestConnection
+	ServerVersion serverVersion
+
+	// Retry définit les nouvelles tentatives des requêtes idempotentes
+	Retry retryPolicy
+
+	// MaxResponseBytes borne la taille lue de chaque réponse
+	MaxResponseBytes int64
+
+	// OCSVersion force ocs/v1.php ou ocs/v2.php ("v1"/"v2") ; vide, chaque
+	// endpoint garde la version choisie par sa table
+	OCSVersion string
+}
+
+// HTTPError est renvoyée lorsque Nextcloud répond avec un statut HTTP 4xx/5xx.
+type HTTPError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("Nextcloud API error %d: %s", e.StatusCode, e.Body)
 }
 
 // ConfigInstance retourne une instance vide de configuration.
@@ -37,51 +132,305 @@ func ConfigInstance() interface{} {
 // NewNextcloudClient crée et valide un NextcloudClient.
 // On y passe *plugin.Connection pour récupérer la config.
 func NewNextcloudClient(ctx context.Context, conn *plugin.Connection) (*NextcloudClient, error) {
+	return newNextcloudClient(ctx, conn, nil)
+}
+
+// newNextcloudClient crée et valide un NextcloudClient. Quand cache n’est pas
+// nil, le résultat du test de connexion y est conservé testedConnectionCacheTTL.
+func newNextcloudClient(ctx context.Context, conn *plugin.Connection, cache *connection.ConnectionCache) (*NextcloudClient, error) {
 	// Récupérer la config (pointer ou valeur)
 	cfg := GetConfig(conn)
 
+	// Transport basé sur http.DefaultTransport (variables d’environnement proxy, proxy_url)
+	transport, err := newTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	client := &NextcloudClient{
+		// Pas de Timeout global : do applique requestTimeout seulement quand le
+		// contexte de la requête n’a pas déjà sa propre échéance
 		HTTPClient: &http.Client{
-			Timeout: 30 *time.Second,
+			Transport: transport,
 		},
 	}
 
-	// Vérifier que server_url est renseigné
-	if cfg.ServerURL != nil && *cfg.ServerURL != "" {
-		client.BaseURL = *cfg.ServerURL
+	// Vérifier que server_url est renseigné (config puis NEXTCLOUD_URL) puis le normaliser
+	if serverURL := configOrEnv(cfg.ServerURL, "NEXTCLOUD_URL"); serverURL != "" {
+		baseURL, err := normalizeServerURL(serverURL)
+		if err != nil {
+			return nil, err
+		}
+		client.BaseURL = baseURL
 	} else {
-		return nil, fmt.Errorf("server_url must be configured")
+		return nil, fmt.Errorf("server_url must be configured (or NEXTCLOUD_URL set)")
 	}
 
-	// Vérifier que username et password sont renseignés
-	if cfg.Username != nil && *cfg.Username != "" {
-		client.Username = *cfg.Username
+	// Vérifier que username et password sont renseignés (config puis variables d’environnement)
+	if username := configOrEnv(cfg.Username, "NEXTCLOUD_USER"); username != "" {
+		client.Username = username
 	} else {
-		return nil, fmt.Errorf("username must be configured")
+		return nil, fmt.Errorf("username must be configured (or NEXTCLOUD_USER set)")
 	}
-	if cfg.Password != nil && *cfg.Password != "" {
-		client.Password = *cfg.Password
+	if password := configOrEnv(cfg.Password, "NEXTCLOUD_PASSWORD", "NEXTCLOUD_APP_TOKEN"); password != "" {
+		client.Password = password
 	} else {
-		return nil, fmt.Errorf("password must be configured")
+		return nil, fmt.Errorf("password must be configured (or NEXTCLOUD_PASSWORD / NEXTCLOUD_APP_TOKEN set)")
+	}
+
+	// Jeton serverinfo optionnel
+	if cfg.ServerinfoToken != nil {
+		client.ServerinfoToken = *cfg.ServerinfoToken
+	}
+
+	// User-Agent : user_agent, sinon celui du plugin
+	client.UserAgent = defaultUserAgent()
+	if cfg.UserAgent != nil && strings.TrimSpace(*cfg.UserAgent) != "" {
+		client.UserAgent = strings.TrimSpace(*cfg.UserAgent)
+	}
+
+	// Langue optionnelle
+	if cfg.Language != nil {
+		client.Language = strings.TrimSpace(*cfg.Language)
+	}
+
+	// Journalisation de débogage optionnelle
+	if cfg.Debug != nil {
+		client.Debug = *cfg.Debug
+	}
+
+	// Taille maximale des réponses
+	client.MaxResponseBytes = defaultMaxResponseBytes
+	if cfg.MaxResponseBytes != nil {
+		if *cfg.MaxResponseBytes <= 0 {
+			return nil, fmt.Errorf("invalid max_response_bytes %d: must be greater than 0", *cfg.MaxResponseBytes)
+		}
+		client.MaxResponseBytes = *cfg.MaxResponseBytes
+	}
+
+	// Paramètres des nouvelles tentatives, validés ici
+	if client.Retry, err = newRetryPolicy(cfg); err != nil {
+		return nil, err
 	}
 
-	// S’assurer que BaseURL se termine par "/"
-	if !strings.HasSuffix(client.BaseURL, "/") {
-		client.BaseURL += "/"
+	// Version OCS forcée, ou détectée d’après la version du serveur ("auto")
+	autoOCSVersion := true
+	if cfg.OCSVersion != nil {
+		switch version := strings.ToLower(strings.TrimSpace(*cfg.OCSVersion)); version {
+		case "v1", "v2":
+			client.OCSVersion = version
+			autoOCSVersion = false
+		case "", "auto":
+		default:
+			return nil, fmt.Errorf("invalid ocs_version %q: must be v1, v2 or auto", *cfg.OCSVersion)
+		}
 	}
 
-	// Tester immédiatement la connexion
+	// Tester la connexion, une seule fois par identifiants tant que le cache
+	// de la connexion la conserve (les requêtes suivantes, comme un get par
+	// clé, évitent cet aller-retour)
+	if cfg.SkipConnectionTest != nil && *cfg.SkipConnectionTest {
+		return client, nil
+	}
+	testedKey := testedConnectionCacheKey(client)
+	if cache != nil {
+		if detected, ok := cache.Get(ctx, testedKey); ok {
+			if autoOCSVersion {
+				client.OCSVersion = detected.(string)
+			}
+			return client, nil
+		}
+	}
 	if err := client.TestConnection(ctx); err != nil {
 		return nil, fmt.Errorf("unable to connect to Nextcloud: %w", err)
 	}
+	// ocs/v2.php n’existe qu’à partir de Nextcloud 9
+	detected := ""
+	if client.ServerVersion.Major > 0 && !client.ServerVersion.AtLeast(9, 0) {
+		detected = "v1"
+	}
+	if cache != nil {
+		if err := cache.SetWithTTL(ctx, testedKey, detected, testedConnectionCacheTTL); err != nil {
+			plugin.Logger(ctx).Warn("newNextcloudClient", "cache_error", err)
+		}
+	}
+	if autoOCSVersion {
+		client.OCSVersion = detected
+	}
 
 	return client, nil
 }
 
+// testedConnectionCacheTTL est la durée pendant laquelle un test de connexion
+// réussi est réutilisé depuis le cache de la connexion.
+const testedConnectionCacheTTL = 5 * time.Minute
+
+// testedConnectionCacheKey est la clé de cache d’un test de connexion réussi,
+// valeur la version OCS détectée pour "auto". Elle dépend de l’URL et des
+// identifiants, le mot de passe n’y figurant que haché.
+func testedConnectionCacheKey(client *NextcloudClient) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{client.BaseURL, client.Username, client.Password}, "\x00")))
+	return "nextcloud_tested_connection:" + hex.EncodeToString(sum[:])
+}
+
+// configOrEnv retourne la valeur de la config si elle est renseignée, sinon la
+// première variable d’environnement non vide parmi envVars.
+func configOrEnv(value *string, envVars ...string) string {
+	if value != nil && *value != "" {
+		return *value
+	}
+	for _, name := range envVars {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// serverEntryPoints sont les points d’entrée de Nextcloud : dans un server_url
+// copié depuis le navigateur ou un client WebDAV, ils marquent la fin du
+// chemin d’installation (https://hote/nextcloud/index.php/apps/files → /nextcloud/).
+var serverEntryPoints = []string{"index.php", "remote.php", "public.php", "status.php", "ocs", "ocs-provider", "ocm-provider"}
+
+// normalizeServerURL nettoie server_url : https:// par défaut si aucun schéma
+// n’est donné, refus des schémas autres que http(s), suppression du point
+// d’entrée éventuel et de ce qui le suit, en conservant le sous-répertoire
+// d’une installation derrière un reverse proxy (https://hote/nextcloud/), et
+// ajout du "/" final attendu par MakeRequest.
+func normalizeServerURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid server_url %q: %w", raw, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("invalid server_url %q: scheme must be http or https", raw)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("invalid server_url %q: missing host", raw)
+	}
+
+	u.Path = installPath(u.Path)
+	u.RawPath = ""
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String(), nil
+}
+
+// installPath réduit le chemin de server_url au répertoire d’installation de
+// Nextcloud, avec un "/" final : "/", "/nextcloud/"...
+func installPath(p string) string {
+	var segments []string
+	for _, segment := range strings.Split(p, "/") {
+		if segment == "" {
+			continue
+		}
+		if slices.Contains(serverEntryPoints, segment) {
+			break
+		}
+		segments = append(segments, segment)
+	}
+	if len(segments) == 0 {
+		return "/"
+	}
+	return "/" + strings.Join(segments, "/") + "/"
+}
+
 // MakeRequest construit et exécute une requête HTTP vers l’API OCS de Nextcloud.
 func (c *NextcloudClient) MakeRequest(ctx context.Context, method, endpoint string, body io.Reader) (*http.Response, error) {
-	// Construire l’URL complète
-	u, err := url.Parse(c.BaseURL + endpoint)
+	req, err := c.newRequest(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+
+	// En-têtes OCS requis
+	req.Header.Set("OCS-APIREQUEST", "true")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+
+	return c.do(req)
+}
+
+// MakeConditionalRequest exécute un GET OCS en ajoutant If-None-Match lorsque
+// etag est renseigné. Le serveur répond alors 304 si la ressource n’a pas changé.
+func (c *NextcloudClient) MakeConditionalRequest(ctx context.Context, endpoint, etag string) (*http.Response, error) {
+	req, err := c.newRequest(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("OCS-APIREQUEST", "true")
+	req.Header.Set("Accept", "application/json")
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	return c.do(req)
+}
+
+// MakeServerInfoRequest exécute un GET OCS vers un endpoint de l’app serverinfo,
+// en ajoutant l’en-tête NC-Token lorsque serverinfo_token est configuré.
+func (c *NextcloudClient) MakeServerInfoRequest(ctx context.Context, endpoint string) (*http.Response, error) {
+	req, err := c.newRequest(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("OCS-APIREQUEST", "true")
+	req.Header.Set("Accept", "application/json")
+	if c.ServerinfoToken != "" {
+		req.Header.Set("NC-Token", c.ServerinfoToken)
+	}
+
+	return c.do(req)
+}
+
+// MakeRawRequest exécute une requête vers un endpoint hors OCS (status.php,
+// ocm-provider, .well-known...) : ni en-têtes OCS ni enveloppe attendue, la
+// réponse est retournée telle quelle. Les identifiants ne sont envoyés que si
+// authenticated est vrai, pour que les endpoints publics répondent même si le
+// compte est bloqué.
+func (c *NextcloudClient) MakeRawRequest(ctx context.Context, method, endpoint string, body io.Reader, authenticated bool) (*http.Response, error) {
+	req, err := c.newRequest(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if !authenticated {
+		req.Header.Del("Authorization")
+	}
+	req.Header.Set("Accept", "application/json, */*;q=0.8")
+
+	return c.do(req)
+}
+
+// MakeDAVRequest construit et exécute une requête WebDAV (PROPFIND, REPORT...) vers remote.php/dav.
+// depth est envoyé dans l’en-tête Depth s’il est renseigné.
+func (c *NextcloudClient) MakeDAVRequest(ctx context.Context, method, endpoint, depth string, body io.Reader) (*http.Response, error) {
+	req, err := c.newRequest(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+
+	// En-têtes WebDAV : corps et réponse en XML
+	req.Header.Set("Accept", "application/xml")
+	req.Header.Set("Content-Type", "application/xml; charset=utf-8")
+	if depth != "" {
+		req.Header.Set("Depth", depth)
+	}
+
+	return c.do(req)
+}
+
+// newRequest prépare une requête authentifiée vers endpoint (relatif à BaseURL).
+func (c *NextcloudClient) newRequest(ctx context.Context, method, endpoint string, body io.Reader) (*http.Request, error) {
+	// Construire l’URL complète : endpoint est relatif au répertoire
+	// d’installation, même s’il commence par "/"
+	u, err := url.Parse(c.BaseURL + c.ocsEndpoint(strings.TrimLeft(endpoint, "/")))
 	if err != nil {
 		return nil, fmt.Errorf("invalid URL: %w", err)
 	}
@@ -91,32 +440,209 @@ func (c *NextcloudClient) MakeRequest(ctx context.Context, method, endpoint stri
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
-
-	// En-têtes OCS requis
-	req.Header.Set("OCS-APIREQUEST", "true")
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", "Steampipe-Nextcloud-Plugin/1.0")
+	req.Header.Set("User-Agent", c.UserAgent)
+	req.Header.Set("Accept-Encoding", "gzip")
+	if c.Language != "" {
+		req.Header.Set("Accept-Language", c.Language)
+	}
 
 	// Basic Auth
 	req.SetBasicAuth(c.Username, c.Password)
 
-	// Exécuter la requête
-	resp, err := c.HTTPClient.Do(req)
+	return req, nil
+}
+
+// ocsEndpoint remplace ocs/v1.php ou ocs/v2.php en tête d’endpoint par la
+// version OCS configurée. Les deux points d’entrée servent les mêmes routes.
+func (c *NextcloudClient) ocsEndpoint(endpoint string) string {
+	if c.OCSVersion == "" {
+		return endpoint
+	}
+	for _, prefix := range []string{"ocs/v1.php/", "ocs/v2.php/"} {
+		if strings.HasPrefix(endpoint, prefix) {
+			return "ocs/" + c.OCSVersion + ".php/" + strings.TrimPrefix(endpoint, prefix)
+		}
+	}
+	return endpoint
+}
+
+// do exécute la requête et transforme les statuts HTTP 4xx/5xx en erreurs.
+func (c *NextcloudClient) do(req *http.Request) (*http.Response, error) {
+	// Échéance : celle du contexte (requête Steampipe) si elle existe, sinon requestTimeout.
+	// L’annulation du contexte interrompt aussi la lecture du corps (PROPFIND en streaming).
+	cancel := context.CancelFunc(func() {})
+	if _, ok := req.Context().Deadline(); !ok {
+		var ctx context.Context
+		ctx, cancel = context.WithTimeout(req.Context(), requestTimeout)
+		req = req.WithContext(ctx)
+	}
+
+	// Exécuter la requête (avec nouvelles tentatives pour les méthodes idempotentes)
+	resp, err := c.doWithRetry(req)
 	if err != nil {
+		cancel()
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
+	// Libérer le contexte à la fermeture du corps
+	resp.Body = cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+
+	// Décompresser les réponses gzip (Accept-Encoding étant fixé par newRequest,
+	// le transport ne les décompresse plus de lui-même)
+	if err := gunzipBody(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	// Borner la taille lue (après décompression)
+	if c.MaxResponseBytes > 0 {
+		resp.Body = &limitedBody{ReadCloser: resp.Body, remaining: c.MaxResponseBytes, limit: c.MaxResponseBytes}
+	}
 
 	// Traiter les statuts HTTP 4xx/5xx comme des erreurs
 	if resp.StatusCode >= 400 {
 		defer resp.Body.Close()
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("Nextcloud API error %d: %s", resp.StatusCode, string(bodyBytes))
+		if c.Debug {
+			c.logResponse(req, resp, bodyBytes)
+		}
+		return nil, &HTTPError{StatusCode: resp.StatusCode, Body: string(bodyBytes)}
+	}
+
+	if c.Debug {
+		// Lire le début du corps sans le consommer pour l’appelant
+		br := bufio.NewReaderSize(resp.Body, debugBodyBytes)
+		peek, _ := br.Peek(debugBodyBytes)
+		c.logResponse(req, resp, peek)
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{br, resp.Body}
 	}
 
 	return resp, nil
 }
 
+// gunzipBody remplace le corps d’une réponse compressée en gzip par sa version décompressée.
+func gunzipBody(resp *http.Response) error {
+	if resp.Uncompressed || !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return nil
+	}
+	gz, err := gzip.NewReader(resp.Body)
+	if err == io.EOF {
+		// corps vide (304, 204...)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("invalid gzip response: %w", err)
+	}
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{gz, resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
+
+// defaultMaxResponseBytes est la taille maximale d’une réponse lorsque max_response_bytes n’est pas renseigné.
+const defaultMaxResponseBytes = 100 << 20
+
+// limitedBody renvoie une erreur explicite dès que plus de limit octets sont lus.
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+	limit     int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		// Vérifier s’il reste réellement des données au-delà de la limite
+		var probe [1]byte
+		if n, _ := b.ReadCloser.Read(probe[:]); n > 0 {
+			return 0, fmt.Errorf("response exceeded max_response_bytes (%d bytes)", b.limit)
+		}
+		return 0, io.EOF
+	}
+	if int64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	return n, err
+}
+
+// requestTimeout est l’échéance d’une requête dont le contexte n’en a pas.
+const requestTimeout = 30 * time.Second
+
+// cancelOnClose annule le contexte d’une requête lorsque son corps est fermé.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// debugBodyBytes est le nombre d’octets du corps journalisés en mode debug.
+const debugBodyBytes = 1024
+
+// logResponse journalise une requête (niveau info) et le début de sa réponse
+// (niveau debug, le corps pouvant contenir mots de passe de partage, e-mails
+// ou jetons). Les en-têtes (Authorization, NC-Token) ne sont jamais
+// journalisés, l’URL est nettoyée et le corps des endpoints sensibles omis.
+func (c *NextcloudClient) logResponse(req *http.Request, resp *http.Response, body []byte) {
+	logger := plugin.Logger(req.Context())
+	logger.Info("nextcloud request",
+		"method", req.Method,
+		"url", sanitizeURL(req.URL),
+		"status", resp.StatusCode,
+	)
+	if isSensitiveEndpoint(req.URL.Path) {
+		return
+	}
+	if len(body) > debugBodyBytes {
+		body = body[:debugBodyBytes]
+	}
+	logger.Debug("nextcloud response",
+		"url", sanitizeURL(req.URL),
+		"body", string(body),
+	)
+}
+
+// sensitiveEndpoints sont les endpoints dont le corps n’est jamais journalisé :
+// jetons de session et mots de passe d’application, données des utilisateurs
+var sensitiveEndpoints = []string{"/settings/personal/authtokens", "/core/apppassword", "/cloud/user"}
+
+// isSensitiveEndpoint indique si le corps des réponses de path ne doit pas être journalisé
+func isSensitiveEndpoint(path string) bool {
+	for _, endpoint := range sensitiveEndpoints {
+		if strings.Contains(path, endpoint) {
+			return true
+		}
+	}
+	return false
+}
+
+// sanitizeURL retourne u sans identifiants ni paramètres sensibles.
+func sanitizeURL(u *url.URL) string {
+	clean := *u
+	clean.User = nil
+	query := clean.Query()
+	for key := range query {
+		switch strings.ToLower(key) {
+		case "password", "token", "app_token", "bearer_token", "access_token":
+			query.Set(key, "REDACTED")
+		}
+	}
+	clean.RawQuery = query.Encode()
+	return clean.String()
+}
+
 // GetJSON effectue un GET et décode la réponse JSON dans 'result'.
 func (c *NextcloudClient) GetJSON(ctx context.Context, endpoint string, result interface{}) error {
 	resp, err := c.MakeRequest(ctx, "GET", endpoint, nil)
@@ -125,17 +651,45 @@ func (c *NextcloudClient) GetJSON(ctx context.Context, endpoint string, result i
 	}
 	defer resp.Body.Close()
 
-	return json.NewDecoder(resp.Body).Decode(result)
+	// Vérifier qu’il s’agit bien de JSON (et pas d’une page HTML de proxy ou de maintenance)
+	body, err := jsonBody(resp)
+	if err != nil {
+		return err
+	}
+	if err := json.NewDecoder(body).Decode(result); err != nil {
+		return fmt.Errorf("%w JSON from %s: %w", ErrDecode, endpoint, err)
+	}
+	return nil
+}
+
+// GetRawJSON effectue un GET hors OCS (voir MakeRawRequest) et décode la
+// réponse JSON dans 'result'.
+func (c *NextcloudClient) GetRawJSON(ctx context.Context, endpoint string, authenticated bool, result interface{}) error {
+	resp, err := c.MakeRawRequest(ctx, "GET", endpoint, nil, authenticated)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := jsonBody(resp)
+	if err != nil {
+		return err
+	}
+	if err := json.NewDecoder(body).Decode(result); err != nil {
+		return fmt.Errorf("%w JSON from %s: %w", ErrDecode, endpoint, err)
+	}
+	return nil
 }
 
 // TestConnection vérifie les identifiants en appelant l’endpoint capabilities.
 func (c *NextcloudClient) TestConnection(ctx context.Context) error {
 	// Exemple : ocs/v1.php/cloud/capabilities?format=json
-	var capabilities map[string]interface{}
+	var capabilities ocsEnvelope[ncCapabilities]
 	err := c.GetJSON(ctx, "ocs/v1.php/cloud/capabilities?format=json", &capabilities)
 	if err != nil {
 		return fmt.Errorf("connection test failed: %w", err)
 	}
+	c.ServerVersion = capabilities.Ocs.Data.Version
 	return nil
 }
 
@@ -154,7 +708,34 @@ func GetConfig(conn *plugin.Connection) *NextcloudConfig {
 	return &NextcloudConfig{}
 }
 
-// GetClient construit et retourne un NextcloudClient validé.
-func GetClient(ctx context.Context, conn *plugin.Connection) (*NextcloudClient, error) {
-	return NewNextcloudClient(ctx, conn)
+// defaultMaxConcurrency est le nombre de requêtes parallèles lorsque max_concurrency n’est pas renseigné.
+const defaultMaxConcurrency = 10
+
+// maxConcurrency retourne max_concurrency, ou defaultMaxConcurrency s’il n’est pas renseigné.
+func maxConcurrency(conn *plugin.Connection) int {
+	cfg := GetConfig(conn)
+	if cfg.MaxConcurrency != nil && *cfg.MaxConcurrency > 0 {
+		return *cfg.MaxConcurrency
+	}
+	return defaultMaxConcurrency
+}
+
+// GetClient construit et retourne un NextcloudClient validé, le test de
+// connexion étant conservé dans le cache de la connexion de d.
+func GetClient(ctx context.Context, d *plugin.QueryData) (*NextcloudClient, error) {
+	return newNextcloudClient(ctx, d.Connection, d.ConnectionCache)
+}
+
+// GetUntestedClient construit un NextcloudClient sans tester la connexion,
+// pour les endpoints qui doivent répondre quand l’API OCS ne le peut pas
+// (status.php pendant une maintenance par exemple).
+func GetUntestedClient(ctx context.Context, conn *plugin.Connection) (*NextcloudClient, error) {
+	cfg := GetConfig(conn)
+	skip := true
+	cfg.SkipConnectionTest = &skip
+	untested := &plugin.Connection{Config: *cfg}
+	if conn != nil {
+		untested.Name = conn.Name
+	}
+	return NewNextcloudClient(ctx, untested)
 }
\ No newline at end of file
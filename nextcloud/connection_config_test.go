@@ -0,0 +1,221 @@
+package nextcloud
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/connection"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// newTestServer starts a mock Nextcloud server and returns a connection to it
+// that skips the connection test
+func newTestServer(t testing.TB, handler http.Handler) (*httptest.Server, *plugin.Connection) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	serverURL, username, password, skip := server.URL, "admin", "secret", true
+	conn := &plugin.Connection{
+		Name: "test",
+		Config: NextcloudConfig{
+			ServerURL:          &serverURL,
+			Username:           &username,
+			Password:           &password,
+			SkipConnectionTest: &skip,
+		},
+	}
+	return server, conn
+}
+
+// newTestClient starts a mock Nextcloud server and returns a client for it
+func newTestClient(t testing.TB, handler http.Handler) *NextcloudClient {
+	t.Helper()
+	_, conn := newTestServer(t, handler)
+	client, err := NewNextcloudClient(context.Background(), conn)
+	if err != nil {
+		t.Fatalf("NewNextcloudClient: %v", err)
+	}
+	return client
+}
+
+// writeOCS writes data wrapped in a successful OCS envelope
+func writeOCS(t testing.TB, w http.ResponseWriter, data interface{}) {
+	t.Helper()
+	w.Header().Set("Content-Type", "application/json")
+	encodeOCS(t, w, data)
+}
+
+// encodeOCS encodes data wrapped in a successful OCS envelope
+func encodeOCS(t testing.TB, w io.Writer, data interface{}) {
+	t.Helper()
+	err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"ocs": map[string]interface{}{
+			"meta": map[string]interface{}{"status": "ok", "statuscode": 200, "message": "OK"},
+			"data": data,
+		},
+	})
+	if err != nil {
+		t.Errorf("encoding OCS response: %v", err)
+	}
+}
+
+func TestNormalizeServerURL(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{name: "no scheme", raw: "cloud.example.com", want: "https://cloud.example.com/"},
+		{name: "no scheme with port", raw: "cloud.example.com:8443", want: "https://cloud.example.com:8443/"},
+		{name: "http kept", raw: "http://cloud.example.com", want: "http://cloud.example.com/"},
+		{name: "trailing slash", raw: "https://cloud.example.com/", want: "https://cloud.example.com/"},
+		{name: "surrounding spaces", raw: "  https://cloud.example.com  ", want: "https://cloud.example.com/"},
+		{name: "index.php suffix", raw: "https://cloud.example.com/index.php", want: "https://cloud.example.com/"},
+		{name: "index.php route", raw: "https://cloud.example.com/index.php/apps/files/?dir=/", want: "https://cloud.example.com/"},
+		{name: "webdav url", raw: "https://cloud.example.com/remote.php/dav/files/alice/", want: "https://cloud.example.com/"},
+		{name: "subdirectory", raw: "https://example.com/nextcloud", want: "https://example.com/nextcloud/"},
+		{name: "subdirectory with entry point", raw: "https://example.com/nextcloud/index.php/login", want: "https://example.com/nextcloud/"},
+		{name: "nested subdirectory", raw: "example.com/apps/nextcloud/ocs/v2.php", want: "https://example.com/apps/nextcloud/"},
+		{name: "query and fragment dropped", raw: "https://cloud.example.com/?foo=bar#top", want: "https://cloud.example.com/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeServerURL(tt.raw)
+			if err != nil {
+				t.Fatalf("normalizeServerURL(%q): %v", tt.raw, err)
+			}
+			if got != tt.want {
+				t.Errorf("normalizeServerURL(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeServerURLInvalid(t *testing.T) {
+	for _, raw := range []string{"", "   ", "https://", "ftp://cloud.example.com", "https://cloud example.com", "https://cloud.example.com:port", "://"} {
+		t.Run(raw, func(t *testing.T) {
+			if got, err := normalizeServerURL(raw); err == nil {
+				t.Errorf("normalizeServerURL(%q) = %q, want an error", raw, got)
+			}
+		})
+	}
+}
+
+func TestDoCanceledMidRequest(t *testing.T) {
+	started := make(chan struct{})
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-r.Context().Done()
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	resp, err := client.MakeRequest(ctx, "GET", "ocs/v2.php/cloud/user?format=json", nil)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatal("expected an error for a canceled request")
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want an error wrapping context.Canceled", err)
+	}
+}
+
+func TestGunzipBody(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != "gzip" {
+			t.Errorf("got Accept-Encoding %q, want gzip", r.Header.Get("Accept-Encoding"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		encodeOCS(t, gz, map[string]string{"id": "admin", "displayname": "Administrator"})
+		if err := gz.Close(); err != nil {
+			t.Errorf("closing gzip writer: %v", err)
+		}
+	}))
+
+	user, err := ocsGetData[struct {
+		ID          string `json:"id"`
+		DisplayName string `json:"displayname"`
+	}](context.Background(), client, "ocs/v2.php/cloud/user?format=json")
+	if err != nil {
+		t.Fatalf("ocsGetData: %v", err)
+	}
+	if user.ID != "admin" || user.DisplayName != "Administrator" {
+		t.Errorf("got %+v, want the decompressed user", user)
+	}
+}
+
+func TestGunzipBodyInvalid(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		_, _ = w.Write([]byte("not gzip"))
+	}))
+
+	resp, err := client.MakeRequest(context.Background(), "GET", "ocs/v2.php/cloud/user?format=json", nil)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatal("expected an error for a corrupt gzip body")
+	}
+}
+
+func TestIsSensitiveEndpoint(t *testing.T) {
+	tests := map[string]bool{
+		"/index.php/settings/personal/authtokens":       true,
+		"/ocs/v2.php/core/apppassword":                  true,
+		"/ocs/v1.php/cloud/users":                       true,
+		"/ocs/v1.php/cloud/users/alice":                 true,
+		"/ocs/v2.php/cloud/user":                        true,
+		"/ocs/v1.php/cloud/capabilities":                false,
+		"/ocs/v2.php/apps/activity/api/v2/activity/all": false,
+	}
+	for path, want := range tests {
+		if got := isSensitiveEndpoint(path); got != want {
+			t.Errorf("isSensitiveEndpoint(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestGetClientCachesConnectionTest(t *testing.T) {
+	var tests atomic.Int32
+	_, conn := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tests.Add(1)
+		writeOCS(t, w, map[string]interface{}{"version": map[string]int{"major": 30}})
+	}))
+	cfg := conn.Config.(NextcloudConfig)
+	skip := false
+	cfg.SkipConnectionTest = &skip
+	conn.Config = cfg
+
+	cache, err := connection.NewConnectionCache(conn.Name, 1000)
+	if err != nil {
+		t.Fatalf("NewConnectionCache: %v", err)
+	}
+	d := &plugin.QueryData{Connection: conn, ConnectionCache: cache}
+	for i := 0; i < 3; i++ {
+		if _, err := GetClient(context.Background(), d); err != nil {
+			t.Fatalf("GetClient: %v", err)
+		}
+	}
+	if got := tests.Load(); got != 1 {
+		t.Errorf("connection tested %d times with a connection cache, want 1", got)
+	}
+
+	if _, err := NewNextcloudClient(context.Background(), conn); err != nil {
+		t.Fatalf("NewNextcloudClient: %v", err)
+	}
+	if got := tests.Load(); got != 2 {
+		t.Errorf("connection tested %d times, want NewNextcloudClient to test it again", got)
+	}
+}
@@ -0,0 +1,160 @@
+package nextcloud
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// davResponse is a single <d:response> of a multistatus document
+type davResponse[P any] struct {
+	Href      string           `xml:"href"`
+	Propstats []davPropstat[P] `xml:"propstat"`
+}
+
+// davPropstat groups properties sharing the same HTTP status
+type davPropstat[P any] struct {
+	Prop   P      `xml:"prop"`
+	Status string `xml:"status"`
+}
+
+// OKProp returns the properties reported with a 200 status, which are the
+// only ones carrying values (missing properties come back as 404 propstats).
+func (r davResponse[P]) OKProp() (P, bool) {
+	for _, ps := range r.Propstats {
+		if strings.Contains(ps.Status, " 200 ") {
+			return ps.Prop, true
+		}
+	}
+	var zero P
+	return zero, false
+}
+
+// davQuery sends a PROPFIND/REPORT request and collects the multistatus responses
+func davQuery[P any](ctx context.Context, client *NextcloudClient, method, endpoint, depth, body string) ([]davResponse[P], error) {
+	var responses []davResponse[P]
+	err := davStream(ctx, client, method, endpoint, depth, body, func(r davResponse[P]) bool {
+		responses = append(responses, r)
+		return true
+	})
+	return responses, err
+}
+
+// davStream sends a PROPFIND/REPORT request and decodes the multistatus
+// response one <d:response> element at a time, calling fn for each of them
+// instead of buffering the whole document. Decoding stops when fn returns
+// false or ctx is cancelled.
+func davStream[P any](ctx context.Context, client *NextcloudClient, method, endpoint, depth, body string, fn func(davResponse[P]) bool) error {
+	resp, err := client.MakeDAVRequest(ctx, method, endpoint, depth, strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	decoder := xml.NewDecoder(resp.Body)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w WebDAV multistatus from %s: %w", ErrDecode, endpoint, err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Space != "DAV:" || start.Name.Local != "response" {
+			continue
+		}
+		var r davResponse[P]
+		if err := decoder.DecodeElement(&r, &start); err != nil {
+			return fmt.Errorf("%w WebDAV response from %s: %w", ErrDecode, endpoint, err)
+		}
+		if !fn(r) {
+			return nil
+		}
+	}
+}
+
+// parseDAVTime parses the date formats used by DAV properties: HTTP dates for
+// getlastmodified and RFC 2822 dates with a numeric zone for oc:creationDateTime.
+// Returns the zero time on failure.
+func parseDAVTime(value string) time.Time {
+	value = strings.TrimSpace(value)
+	if t, err := http.ParseTime(value); err == nil {
+		return t
+	}
+	if t, err := time.Parse(time.RFC1123Z, value); err == nil {
+		return t
+	}
+	return time.Time{}
+}
+
+// adminGroup is the group granting admin rights
+const adminGroup = "admin"
+
+// davTargetUser returns the user whose WebDAV tree is read: the user of an
+// "as_user = X" qualifier, which requires admin credentials, or the
+// configured user
+func davTargetUser(ctx context.Context, d *plugin.QueryData, client *NextcloudClient) (string, error) {
+	qual := d.EqualsQuals["as_user"]
+	if qual == nil || qual.GetStringValue() == "" || qual.GetStringValue() == client.Username {
+		return client.Username, nil
+	}
+	user := qual.GetStringValue()
+
+	admin, err := isAdmin(ctx, d, client)
+	if err != nil {
+		return "", fmt.Errorf("unable to check the admin rights required by as_user: %w", err)
+	}
+	if !admin {
+		return "", notAdminError(client, user, nil)
+	}
+	return user, nil
+}
+
+// isAdmin reports whether the configured account is in the admin group,
+// kept in the connection cache
+func isAdmin(ctx context.Context, d *plugin.QueryData, client *NextcloudClient) (bool, error) {
+	cacheKey := "nextcloud_is_admin:" + client.Username
+	if cached, ok := d.ConnectionCache.Get(ctx, cacheKey); ok {
+		return cached.(bool), nil
+	}
+
+	groups, err := listUserGroupIDs(ctx, client, client.Username)
+	if err != nil {
+		return false, err
+	}
+	admin := slices.Contains(groups, adminGroup)
+	if err := d.ConnectionCache.SetWithTTL(ctx, cacheKey, admin, capabilitiesCacheTTL); err != nil {
+		plugin.Logger(ctx).Warn("isAdmin", "cache_error", err)
+	}
+	return admin, nil
+}
+
+// asUserError turns a 403 on the tree of another user (as_user) into an
+// explicit admin rights error
+func asUserError(client *NextcloudClient, user string, err error) error {
+	if err != nil && user != client.Username && errors.Is(err, ErrForbidden) {
+		return notAdminError(client, user, err)
+	}
+	return err
+}
+
+// notAdminError reports that the configured account can't read the files of user
+func notAdminError(client *NextcloudClient, user string, err error) error {
+	if err == nil {
+		err = ErrForbidden
+	}
+	return fmt.Errorf("as_user = %q requires admin credentials: the configured account %s is not an admin: %w", user, client.Username, err)
+}
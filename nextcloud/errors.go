@@ -0,0 +1,54 @@
+package nextcloud
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Sentinel errors, matched with errors.Is against the errors returned by the
+// client and the decode helpers, whatever their concrete type (*HTTPError,
+// *OCSError, *appDisabledError or a wrapped decode error)
+var (
+	// ErrUnauthorized: the credentials were rejected (HTTP 401, OCS 997)
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden: the account lacks the permission (HTTP 403), e.g. a non-admin on a provisioning endpoint
+	ErrForbidden = errors.New("forbidden")
+	// ErrNotFound: the resource or route does not exist (HTTP 404, OCS 998)
+	ErrNotFound = errors.New("not found")
+	// ErrServer: the server failed to process the request (HTTP 5xx, OCS 999)
+	ErrServer = errors.New("server error")
+	// ErrAppNotEnabled: the app behind the endpoint is not installed or not enabled
+	ErrAppNotEnabled = errors.New("app not enabled")
+	// ErrDecode: the response could not be decoded (not JSON/XML, or unexpected shape)
+	ErrDecode = errors.New("error decoding")
+)
+
+// statusSentinel returns the sentinel error matching an HTTP status or an OCS statuscode
+func statusSentinel(code int) error {
+	switch {
+	case code == http.StatusUnauthorized || code == 997:
+		return ErrUnauthorized
+	case code == http.StatusForbidden:
+		return ErrForbidden
+	case code == http.StatusNotFound || code == 998:
+		return ErrNotFound
+	case code >= 500 && code <= 599, code == 999:
+		return ErrServer
+	}
+	return nil
+}
+
+// Is makes errors.Is(err, ErrUnauthorized) and friends match on the HTTP status
+func (e *HTTPError) Is(target error) bool {
+	return target != nil && statusSentinel(e.StatusCode) == target
+}
+
+// Is makes errors.Is(err, ErrUnauthorized) and friends match on the OCS statuscode
+func (e *OCSError) Is(target error) bool {
+	return target != nil && statusSentinel(e.Code) == target
+}
+
+// Is makes errors.Is(err, ErrAppNotEnabled) match; the wrapped error still matches ErrNotFound
+func (e *appDisabledError) Is(target error) bool {
+	return target == ErrAppNotEnabled
+}
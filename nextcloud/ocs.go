@@ -0,0 +1,225 @@
+package nextcloud
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// ocsMeta is the "meta" block of every OCS response
+type ocsMeta struct {
+	Status     string `json:"status"`
+	StatusCode int    `json:"statuscode"`
+	Message    string `json:"message"`
+}
+
+// ocsEnvelope is the standard OCS response envelope with a data member of type D
+type ocsEnvelope[D any] struct {
+	Ocs struct {
+		Meta ocsMeta `json:"meta"`
+		Data D       `json:"data"`
+	} `json:"ocs"`
+}
+
+// OCSError is returned when the OCS API reports a failure in its meta block.
+// Nextcloud often does so with an HTTP 200 status (e.g. 997 when the user is
+// not logged in, 998 when the resource is not found).
+type OCSError struct {
+	Code    int
+	Message string
+}
+
+func (e *OCSError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("OCS error %d", e.Code)
+	}
+	return fmt.Sprintf("OCS error %d: %s", e.Code, e.Message)
+}
+
+// ocsGet performs a GET on an OCS endpoint whose data member is a list and returns its items
+func ocsGet[T any](ctx context.Context, client *NextcloudClient, endpoint string) ([]T, error) {
+	return ocsGetData[[]T](ctx, client, endpoint)
+}
+
+// ocsGetData performs a GET on an OCS endpoint, checks the meta block and returns the decoded data member
+func ocsGetData[D any](ctx context.Context, client *NextcloudClient, endpoint string) (D, error) {
+	resp, err := client.MakeRequest(ctx, "GET", endpoint, nil)
+	if err != nil {
+		var zero D
+		return zero, ocsErrorFromHTTP(err)
+	}
+	defer resp.Body.Close()
+	return ocsDecode[D](resp, endpoint)
+}
+
+// paginateOCS pages through an OCS endpoint using a cursor: the value of the
+// lastGivenHeader response header (e.g. X-Activity-Last-Given) is sent back in
+// cursorParam to get the next page. Each decoded batch is passed to fn; paging
+// stops when fn returns false, on an empty batch or a 304 Not Modified, when
+// the cursor does not move, or when ctx is cancelled.
+func paginateOCS[T any](ctx context.Context, client *NextcloudClient, baseEndpoint, cursorParam, lastGivenHeader string, fn func([]T) bool) error {
+	separator := "?"
+	if strings.Contains(baseEndpoint, "?") {
+		separator = "&"
+	}
+
+	cursor := ""
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		endpoint := baseEndpoint
+		if cursor != "" {
+			endpoint += separator + cursorParam + "=" + url.QueryEscape(cursor)
+		}
+
+		resp, err := client.MakeRequest(ctx, "GET", endpoint, nil)
+		if err != nil {
+			return ocsErrorFromHTTP(err)
+		}
+		// 304: nothing after the cursor
+		if resp.StatusCode == http.StatusNotModified {
+			resp.Body.Close()
+			return nil
+		}
+		batch, err := ocsDecode[[]T](resp, endpoint)
+		next := resp.Header.Get(lastGivenHeader)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		if len(batch) == 0 || !fn(batch) {
+			return nil
+		}
+		if next == "" || next == cursor {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// ocsDecode decodes an OCS envelope from resp and surfaces meta failures as *OCSError
+func ocsDecode[D any](resp *http.Response, endpoint string) (D, error) {
+	var zero D
+	var result ocsEnvelope[json.RawMessage]
+	body, err := jsonBody(resp)
+	if err != nil {
+		return zero, err
+	}
+	if err := json.NewDecoder(body).Decode(&result); err != nil {
+		return zero, fmt.Errorf("%w JSON from %s: %w", ErrDecode, endpoint, err)
+	}
+	if err := result.Ocs.Meta.err(); err != nil {
+		return zero, err
+	}
+	return ocsData[D](result.Ocs.Data, endpoint)
+}
+
+// ocsData decodes the data member of an OCS envelope. Endpoints without
+// results answer null, {} or [] depending on the app (PHP encodes an empty
+// array as [] whether it is a list or a map), so these are all decoded as the
+// zero value of D instead of failing on a type mismatch.
+func ocsData[D any](raw json.RawMessage, endpoint string) (D, error) {
+	var data D
+	if _, ok := any(data).(json.RawMessage); ok {
+		return any(raw).(D), nil
+	}
+
+	trimmed := string(bytes.TrimSpace(raw))
+	kind := reflect.TypeOf(&data).Elem().Kind()
+	isList := kind == reflect.Slice || kind == reflect.Array
+	switch {
+	case trimmed == "" || trimmed == "null":
+		return data, nil
+	case trimmed == "{}" && isList, trimmed == "[]" && !isList:
+		return data, nil
+	}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return data, fmt.Errorf("%w JSON from %s: %w", ErrDecode, endpoint, err)
+	}
+	return data, nil
+}
+
+// err returns an *OCSError when the meta block reports a failure. OCS v1
+// reports success with statuscode 100 and v2 with the HTTP-like 200.
+func (m ocsMeta) err() error {
+	if m.Status == "ok" && (m.StatusCode == 0 || m.StatusCode == 100 || m.StatusCode == 200) {
+		return nil
+	}
+	return &OCSError{Code: m.StatusCode, Message: m.Message}
+}
+
+// ocsErrorFromHTTP turns an HTTP error whose body is an OCS envelope (as OCS v2
+// returns for 401/404...) into the more descriptive *OCSError.
+func ocsErrorFromHTTP(err error) error {
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		return err
+	}
+	var result ocsEnvelope[json.RawMessage]
+	if json.Unmarshal([]byte(httpErr.Body), &result) != nil || result.Ocs.Meta.Status == "" {
+		return err
+	}
+	return &OCSError{Code: result.Ocs.Meta.StatusCode, Message: result.Ocs.Meta.Message}
+}
+
+// jsonBody returns a reader over resp.Body after checking that the server
+// really answered with JSON. A reverse proxy login page or a maintenance page
+// otherwise ends up as a cryptic "invalid character '<'" decode error.
+func jsonBody(resp *http.Response) (io.Reader, error) {
+	br := bufio.NewReader(resp.Body)
+	peek, _ := br.Peek(512)
+	trimmed := bytes.TrimLeft(peek, " \t\r\n")
+
+	contentType := resp.Header.Get("Content-Type")
+	looksJSON := len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[')
+	if looksJSON || (len(trimmed) == 0 && strings.Contains(contentType, "json")) {
+		return br, nil
+	}
+
+	if contentType == "" {
+		contentType = "unknown content type"
+	}
+	snippet := trimmed
+	if len(snippet) > 200 {
+		snippet = snippet[:200]
+	}
+	return nil, fmt.Errorf("%w: expected JSON from Nextcloud but got %s (check server_url and that OCS is enabled): %s", ErrDecode, contentType, snippet)
+}
+
+// appDisabledError is returned when the routes of an app answer 404 because
+// the app is not installed or not enabled
+type appDisabledError struct {
+	App string
+	Err error
+}
+
+func (e *appDisabledError) Error() string {
+	return fmt.Sprintf("the %s app does not seem to be enabled on this Nextcloud server: %v", e.App, e.Err)
+}
+
+func (e *appDisabledError) Unwrap() error {
+	return e.Err
+}
+
+// appNotEnabledError rewrites the 404 returned for the routes of an app that
+// is not installed or not enabled into an *appDisabledError. Other errors are
+// returned unchanged.
+func appNotEnabledError(app string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, ErrNotFound) {
+		return &appDisabledError{App: app, Err: err}
+	}
+	return err
+}
@@ -0,0 +1,81 @@
+package nextcloud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+// rawOCSHandler answers every request with an OCS envelope whose data member is data, verbatim
+func rawOCSHandler(data string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"ocs":{"meta":{"status":"ok","statuscode":200,"message":"OK"},"data":%s}}`, data)
+	})
+}
+
+// emptyOCSData are the shapes Nextcloud uses for an empty data member
+var emptyOCSData = []string{"null", "{}", "[]"}
+
+func TestOcsGetSharesEmptyData(t *testing.T) {
+	for _, data := range emptyOCSData {
+		t.Run(data, func(t *testing.T) {
+			client := newTestClient(t, rawOCSHandler(data))
+			shares, err := ocsGet[ocsShare](context.Background(), client, "ocs/v2.php/apps/files_sharing/api/v1/shares?format=json")
+			if err != nil {
+				t.Fatalf("ocsGet: %v", err)
+			}
+			if len(shares) != 0 {
+				t.Errorf("got %d shares, want none", len(shares))
+			}
+		})
+	}
+}
+
+func TestOcsGetShares(t *testing.T) {
+	client := newTestClient(t, rawOCSHandler(`[{"id":"7","share_type":3,"path":"/Photos"}]`))
+	shares, err := ocsGet[ocsShare](context.Background(), client, "ocs/v2.php/apps/files_sharing/api/v1/shares?format=json")
+	if err != nil {
+		t.Fatalf("ocsGet: %v", err)
+	}
+	if len(shares) != 1 || shares[0].ID != "7" || shares[0].Path != "/Photos" {
+		t.Errorf("got %+v, want the share 7 of /Photos", shares)
+	}
+}
+
+func TestListUserIDsEmptyData(t *testing.T) {
+	for _, data := range append(emptyOCSData, `{"users":[]}`) {
+		t.Run(data, func(t *testing.T) {
+			client := newTestClient(t, rawOCSHandler(data))
+			ids, err := listUserIDs(context.Background(), client)
+			if err != nil {
+				t.Fatalf("listUserIDs: %v", err)
+			}
+			if len(ids) != 0 {
+				t.Errorf("got users %v, want none", ids)
+			}
+		})
+	}
+}
+
+func TestListUserIDs(t *testing.T) {
+	client := newTestClient(t, rawOCSHandler(`{"users":["admin","alice"]}`))
+	ids, err := listUserIDs(context.Background(), client)
+	if err != nil {
+		t.Fatalf("listUserIDs: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != "admin" || ids[1] != "alice" {
+		t.Errorf("got users %v, want [admin alice]", ids)
+	}
+}
+
+func TestOcsDataMismatch(t *testing.T) {
+	// a non-empty object where a list is expected is still an error
+	client := newTestClient(t, rawOCSHandler(`{"id":"7"}`))
+	_, err := ocsGet[ocsShare](context.Background(), client, "ocs/v2.php/apps/files_sharing/api/v1/shares?format=json")
+	if !errors.Is(err, ErrDecode) {
+		t.Errorf("got %v, want an error wrapping ErrDecode", err)
+	}
+}
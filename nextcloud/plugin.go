@@ -19,6 +19,46 @@ func Plugin(ctx context.Context) *plugin.Plugin {
         TableMap: map[string]*plugin.Table{
             "nextcloud_activity": tableNextcloudActivity(),
             "nextcloud_share": tableNextcloudShare(),
+            "nextcloud_user_status": tableNextcloudUserStatus(),
+            "nextcloud_quota": tableNextcloudQuota(),
+            "nextcloud_comment": tableNextcloudComment(),
+            "nextcloud_server_info": tableNextcloudServerInfo(),
+            "nextcloud_federated_server": tableNextcloudFederatedServer(),
+            "nextcloud_circle": tableNextcloudCircle(),
+            "nextcloud_sharee": tableNextcloudSharee(),
+            "nextcloud_notes": tableNextcloudNotes(),
+            "nextcloud_bookmark": tableNextcloudBookmark(),
+            "nextcloud_file": tableNextcloudFile(),
+            "nextcloud_app_config": tableNextcloudAppConfig(),
+            "nextcloud_addressbook": tableNextcloudAddressbook(),
+            "nextcloud_contact": tableNextcloudContact(),
+            "nextcloud_log": tableNextcloudLog(),
+            "nextcloud_announcement": tableNextcloudAnnouncement(),
+            "nextcloud_weather_status": tableNextcloudWeatherStatus(),
+            "nextcloud_two_factor": tableNextcloudTwoFactor(),
+            "nextcloud_user_group": tableNextcloudUserGroup(),
+            "nextcloud_group_member": tableNextcloudGroupMember(),
+            "nextcloud_recommendation": tableNextcloudRecommendation(),
+            "nextcloud_poll": tableNextcloudPoll(),
+            "nextcloud_storage_stat": tableNextcloudStorageStat(),
+            "nextcloud_app_update": tableNextcloudAppUpdate(),
+            "nextcloud_mail_account": tableNextcloudMailAccount(),
+            "nextcloud_bruteforce": tableNextcloudBruteforce(),
+            "nextcloud_file_search": tableNextcloudFileSearch(),
+            "nextcloud_tag_assignment": tableNextcloudTagAssignment(),
+            "nextcloud_user": tableNextcloudUser(),
+            "nextcloud_disabled_user": tableNextcloudDisabledUser(),
+            "nextcloud_activity_type": tableNextcloudActivityType(),
+            "nextcloud_status": tableNextcloudStatus(),
+            "nextcloud_ocm_provider": tableNextcloudOCMProvider(),
+            "nextcloud_capability_check": tableNextcloudCapabilityCheck(),
+            "nextcloud_workflow": tableNextcloudWorkflow(),
+            "nextcloud_share_readiness": tableNextcloudShareReadiness(),
+            "nextcloud_storage_overview": tableNextcloudStorageOverview(),
+            "nextcloud_trashbin": tableNextcloudTrashbin(),
+            "nextcloud_app_store": tableNextcloudAppStore(),
+            "nextcloud_notification": tableNextcloudNotification(),
+            "nextcloud_version": tableNextcloudVersion(),
         },
     }
 
@@ -35,4 +75,52 @@ var configSchema = map[string]*schema.Attribute{
     "password": {
         Type: schema.TypeString,
     },
+    "serverinfo_token": {
+        Type: schema.TypeString,
+    },
+    "proxy_url": {
+        Type: schema.TypeString,
+    },
+    "client_cert": {
+        Type: schema.TypeString,
+    },
+    "client_key": {
+        Type: schema.TypeString,
+    },
+    "tls_min_version": {
+        Type: schema.TypeString,
+    },
+    "language": {
+        Type: schema.TypeString,
+    },
+    "debug": {
+        Type: schema.TypeBool,
+    },
+    "skip_connection_test": {
+        Type: schema.TypeBool,
+    },
+    "ocs_version": {
+        Type: schema.TypeString,
+    },
+    "max_concurrency": {
+        Type: schema.TypeInt,
+    },
+    "retry_base_ms": {
+        Type: schema.TypeInt,
+    },
+    "retry_max_ms": {
+        Type: schema.TypeInt,
+    },
+    "max_retries": {
+        Type: schema.TypeInt,
+    },
+    "max_response_bytes": {
+        Type: schema.TypeInt,
+    },
+    "user_agent": {
+        Type: schema.TypeString,
+    },
+    "trashbin_retention_days": {
+        Type: schema.TypeInt,
+    },
 }
\ No newline at end of file
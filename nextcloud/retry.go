@@ -0,0 +1,131 @@
+package nextcloud
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Default retry settings, used when retry_base_ms, retry_max_ms and max_retries are not set
+const (
+	defaultRetryBase  = 200 * time.Millisecond
+	defaultRetryMax   = 5 * time.Second
+	defaultMaxRetries = 3
+)
+
+// retryPolicy holds the backoff settings of a client
+type retryPolicy struct {
+	Base       time.Duration
+	Max        time.Duration
+	MaxRetries int
+}
+
+// newRetryPolicy builds the retry policy from the connection config and validates its ranges
+func newRetryPolicy(cfg *NextcloudConfig) (retryPolicy, error) {
+	policy := retryPolicy{Base: defaultRetryBase, Max: defaultRetryMax, MaxRetries: defaultMaxRetries}
+	if cfg.RetryBaseMS != nil {
+		if *cfg.RetryBaseMS <= 0 {
+			return policy, fmt.Errorf("invalid retry_base_ms %d: must be greater than 0", *cfg.RetryBaseMS)
+		}
+		policy.Base = time.Duration(*cfg.RetryBaseMS) * time.Millisecond
+	}
+	if cfg.RetryMaxMS != nil {
+		policy.Max = time.Duration(*cfg.RetryMaxMS) * time.Millisecond
+	}
+	if policy.Max < policy.Base {
+		return policy, fmt.Errorf("invalid retry_max_ms %d: must be at least retry_base_ms (%d)", policy.Max.Milliseconds(), policy.Base.Milliseconds())
+	}
+	if cfg.MaxRetries != nil {
+		if *cfg.MaxRetries < 0 || *cfg.MaxRetries > 10 {
+			return policy, fmt.Errorf("invalid max_retries %d: must be between 0 and 10", *cfg.MaxRetries)
+		}
+		policy.MaxRetries = *cfg.MaxRetries
+	}
+	return policy, nil
+}
+
+// backoff returns the wait before retry number attempt (0-based), using full
+// jitter: a random duration between 0 and min(Max, Base*2^attempt), so that
+// concurrent queries hitting the same server do not retry in lockstep
+func (p retryPolicy) backoff(attempt int) time.Duration {
+	ceiling := p.Max
+	if attempt < 30 {
+		if d := p.Base << attempt; d > 0 && d < ceiling {
+			ceiling = d
+		}
+	}
+	return time.Duration(rand.Int63n(int64(ceiling) + 1))
+}
+
+// doWithRetry sends req, retrying idempotent requests on network errors and
+// on 429/502/503/504 responses. A Retry-After header is honored when it does
+// not exceed the maximum backoff.
+func (c *NextcloudClient) doWithRetry(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := c.HTTPClient.Do(req)
+		if attempt >= c.Retry.MaxRetries || !isIdempotent(req.Method) || !isRetryable(resp, err) || req.Context().Err() != nil {
+			return resp, err
+		}
+		// the body of the request must be replayable
+		if req.Body != nil && req.GetBody == nil {
+			return resp, err
+		}
+
+		wait := c.Retry.backoff(attempt)
+		if resp != nil {
+			if after := retryAfter(resp); after > 0 && after <= c.Retry.Max {
+				wait = after
+			}
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+			resp.Body.Close()
+		}
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// isIdempotent reports whether a request with this method can safely be sent again
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, "PROPFIND", "REPORT", "SEARCH":
+		return true
+	}
+	return false
+}
+
+// isRetryable reports whether the outcome of a request is worth a retry
+func isRetryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter returns the delay of a Retry-After header given in seconds, or 0
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
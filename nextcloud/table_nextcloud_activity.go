@@ -2,8 +2,9 @@ package nextcloud
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"net/url"
+	"regexp"
 	"strconv"
 	"time"
 
@@ -15,46 +16,53 @@ import (
 // Activity représente une entrée d'activité depuis l'API Activity de Nextcloud.
 // On déclare SubjectRich comme interface{} pour accepter un tableau ou un bool selon la version de Nextcloud.
 type Activity struct {
-	ActivityID    int64       `json:"activity_id"`
-	App           string      `json:"app"`
-	Type          string      `json:"type"`
-	Subject       string      `json:"subject"`
-	SubjectRich   interface{} `json:"subject_rich"`
-	SubjectParams []string    `json:"subject_params"`
-	ObjectType    string      `json:"object_type"`
-	ObjectID      int         `json:"object_id"`
-	ObjectName    string      `json:"object_name"`
-	Time          time.Time   `json:"datetime"`
-	User          string      `json:"user"`
+	ActivityID    int64             `json:"activity_id"`
+	App           string            `json:"app"`
+	Type          string            `json:"type"`
+	Subject       string            `json:"subject"`
+	SubjectRich   interface{}       `json:"subject_rich"`
+	SubjectParams []string          `json:"subject_params"`
+	ObjectType    string            `json:"object_type"`
+	ObjectID      int               `json:"object_id"`
+	ObjectName    string            `json:"object_name"`
+	Time          time.Time         `json:"datetime"`
+	User          string            `json:"user"`
+	Icon          string            `json:"icon"`
+	Link          string            `json:"link"`
+	Previews      []activityPreview `json:"previews"`
 }
 
-// ocsActivityListResponse wrappe l'enveloppe JSON renvoyée par l'API Activity.
-type ocsActivityListResponse struct {
-	Ocs struct {
-		Meta struct {
-			Status     string `json:"status"`
-			StatusCode int    `json:"statuscode"`
-			Message    string `json:"message"`
-		} `json:"meta"`
-		Data []Activity `json:"data"`
-	} `json:"ocs"`
+// activityPreview décrit la miniature d'un fichier concerné par une activité.
+type activityPreview struct {
+	Link           string `json:"link"`
+	Source         string `json:"source"`
+	MimeType       string `json:"mimeType"`
+	IsMimeTypeIcon bool   `json:"isMimeTypeIcon"`
+	FileID         int64  `json:"fileId"`
+	View           string `json:"view"`
+	Filename       string `json:"filename"`
 }
 
 // tableNextcloudActivity définit le schéma de la table "nextcloud_activity".
 func tableNextcloudActivity() *plugin.Table {
 	return &plugin.Table{
 		Name:        "nextcloud_activity",
-		Description: "Nextcloud activity events (from the Activity app)",
+		Description: "Nextcloud activity events (from the Activity app). The Activity API has no read or seen state",
 		List: &plugin.ListConfig{
-			Hydrate: listActivity,
+			Hydrate: listIfAppEnabled("activity", listActivity),
+			KeyColumns: plugin.KeyColumnSlice{
+				{Name: "app", Require: plugin.Optional},
+				{Name: "user_id", Require: plugin.Optional},
+				{Name: "filter", Require: plugin.Optional},
+			},
 		},
 		Get: &plugin.GetConfig{
 			KeyColumns: plugin.SingleColumn("id"),
 			Hydrate:    getActivity,
 		},
-		Columns: []*plugin.Column{
+		Columns: commonColumns([]*plugin.Column{
 			{Name: "id", Type: proto.ColumnType_STRING, Description: "Activity ID", Transform: transform.FromField("ActivityID").Transform(transform.ToString)},
-			{Name: "app", Type: proto.ColumnType_STRING, Description: "Originating app", Transform: transform.FromField("App")},
+			{Name: "app", Type: proto.ColumnType_STRING, Description: "Originating app. Filtered server-side for files, files_sharing, comments and deck, client-side otherwise", Transform: transform.FromField("App")},
 			{Name: "type", Type: proto.ColumnType_STRING, Description: "Activity type", Transform: transform.FromField("Type")},
 			{Name: "subject", Type: proto.ColumnType_STRING, Description: "Unformatted subject", Transform: transform.FromField("Subject")},
 			{Name: "time", Type: proto.ColumnType_TIMESTAMP, Description: "Timestamp of the activity", Transform: transform.FromField("Time")},
@@ -65,58 +73,71 @@ func tableNextcloudActivity() *plugin.Table {
 			{Name: "object_name", Type: proto.ColumnType_STRING, Description: "Name of the object", Transform: transform.FromField("ObjectName")},
 			
 			{Name: "user", Type: proto.ColumnType_STRING, Description: "User who performed the action", Transform: transform.FromField("User")},
-		},
+			{Name: "user_id", Type: proto.ColumnType_STRING, Description: "User who performed the action. The Activity API only returns the stream of the configured user, even for admins: filtered server-side when it is the configured user (self filter), client-side otherwise", Transform: transform.FromField("User")},
+			{Name: "icon", Type: proto.ColumnType_STRING, Description: "URL of the icon displayed for the activity", Transform: transform.FromField("Icon").NullIfZero()},
+			{Name: "link", Type: proto.ColumnType_STRING, Description: "Link to the object of the activity in the Nextcloud UI", Transform: transform.FromField("Link").NullIfZero()},
+			{Name: "filter", Type: proto.ColumnType_STRING, Description: "Stream to read: all (default, everything the configured user can see), self (their own actions), by (actions of others) or an app filter ID listed by nextcloud_activity_type (files, calendar...). Admins only see activities of files and apps shared with them, not the whole server", Transform: transform.FromQual("filter")},
+			{Name: "previews", Type: proto.ColumnType_JSON, Description: "Previews (thumbnails) of the files the activity is about", Transform: transform.FromField("Previews").NullIfZero()},
+		}),
 	}
 }
 
-// listActivity appelle l'endpoint OCS pour lister toutes les activités.
+// activityPageSize est le nombre d'activités demandées par page
+const activityPageSize = 100
+
+// listActivity parcourt les pages de l'API Activity et diffuse les activités.
 func listActivity(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
 	// Construire le client à partir de d.Connection
-	client, err := GetClient(ctx, d.Connection)
+	client, err := GetClient(ctx, d)
 	if err != nil {
 		return nil, err
 	}
 
-	// Endpoint Nextcloud Activity (format JSON)
-	endpoint := "ocs/v2.php/apps/activity/api/v2/activity?format=json"
-
-	// Appel HTTP GET
-	resp, err := client.MakeRequest(ctx, "GET", endpoint, nil)
-	if err != nil {
-		return nil, err
+	// Si un filtre "user_id = X" est présent, on ne diffuse que les activités correspondant à user == userID
+	userID := ""
+	if qual := d.EqualsQuals["user_id"]; qual != nil {
+		userID = qual.GetStringValue()
 	}
-	defer resp.Body.Close()
 
-	// Décodage de l'enveloppe JSON
-	var result ocsActivityListResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("échec du décodage JSON Nextcloud Activity : %w", err)
+	// Filtre "app = X" : filtre de l'API Activity quand il en existe un,
+	// vérifié de nouveau côté client (un filtre peut couvrir plusieurs apps)
+	app, filter := "", "all"
+	if qual := d.EqualsQuals["app"]; qual != nil {
+		app = qual.GetStringValue()
+		if f, ok := activityAppFilters[app]; ok {
+			filter = f
+		}
 	}
 
-	// Vérification du statut OCS
-	if result.Ocs.Meta.Status != "ok" {
-		return nil, fmt.Errorf("erreur OCS API : %s (code : %d)", result.Ocs.Meta.Message, result.Ocs.Meta.StatusCode)
+	// L'API Activity ne donne accès qu'au flux de l'utilisateur configuré,
+	// même pour un admin : seules ses propres actions peuvent être filtrées
+	// côté serveur (filtre "self"), les autres utilisateurs le sont côté client
+	if filter == "all" && userID != "" && userID == client.Username {
+		filter = "self"
 	}
 
-	// Si un filtre "user_id = X" est présent, on ne diffuse que les activités correspondant à user == userID
-	if qual := d.EqualsQuals["user_id"]; qual != nil {
-		userID := qual.GetStringValue()
-		for _, activity := range result.Ocs.Data {
-			if activity.User == userID {
-				d.StreamListItem(ctx, activity)
-			}
-		}
-	} else {
-		// pas de filtre, on diffuse toutes les activités
-		for _, activity := range result.Ocs.Data {
-			d.StreamListItem(ctx, activity)
+	// Filtre "filter = X" explicite (all, self, by ou un filtre d'app listé par
+	// nextcloud_activity_type) : il remplace le filtre choisi ci-dessus, l'app
+	// étant alors filtrée côté client
+	if qual := d.EqualsQuals["filter"]; qual != nil {
+		filter = qual.GetStringValue()
+		if !activityFilterID.MatchString(filter) {
+			return nil, fmt.Errorf("invalid filter %q: must be all, self, by or a filter ID of nextcloud_activity_type", filter)
 		}
 	}
 
-	return nil, nil
+	err = forEachActivity(ctx, client, filter, func(activity Activity) bool {
+		if (userID == "" || activity.User == userID) && (app == "" || activity.App == app) {
+			d.StreamListItem(ctx, activity)
+		}
+		return d.RowsRemaining(ctx) != 0
+	})
+	return nil, err
 }
 
-// getActivity récupère une activité précise via son ID.
+// getActivity récupère une activité précise via son ID. L'API Activity n'ayant
+// pas d'endpoint par activité, les pages sont parcourues (curseur "since")
+// jusqu'à trouver l'ID ou épuiser l'historique.
 func getActivity(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
 	// Extraction du qualifier "id" depuis d.EqualsQuals
 	qual := d.EqualsQuals["id"]
@@ -124,44 +145,63 @@ func getActivity(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData
 		return nil, fmt.Errorf("id qualifier not provided")
 	}
 	id := qual.GetStringValue()
-	
+
 	// Conversion de l'ID string en int64 pour la comparaison
 	idInt, err := strconv.ParseInt(id, 10, 64)
 	if err != nil {
 		return nil, fmt.Errorf("invalid ID format: %s", id)
 	}
-	
+
 	// Construire le client Nextcloud
-	client, err := GetClient(ctx, d.Connection)
+	client, err := GetClient(ctx, d)
 	if err != nil {
 		return nil, err
 	}
-	
-	// Récupérer toutes les activités (filtrage côté client)
-	endpoint := "ocs/v2.php/apps/activity/api/v2/activity?format=json"
-	resp, err := client.MakeRequest(ctx, "GET", endpoint, nil)
+
+	// Les activités sont triées de la plus récente à la plus ancienne : on
+	// s'arrête dès que l'ID cherché est trouvé ou dépassé
+	var found *Activity
+	err = forEachActivity(ctx, client, "all", func(activity Activity) bool {
+		if activity.ActivityID == idInt {
+			found = &activity
+		}
+		return found == nil && activity.ActivityID > idInt
+	})
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-	
-	// Décodage de l'enveloppe JSON
-	var result ocsActivityListResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("échec du décodage JSON Nextcloud Activity : %w", err)
-	}
-	
-	if result.Ocs.Meta.Status != "ok" {
-		return nil, fmt.Errorf("OCS API error: %s (code: %d)", result.Ocs.Meta.Message, result.Ocs.Meta.StatusCode)
+	if found == nil {
+		return nil, fmt.Errorf("activity with ID %s not found", id)
 	}
-	
-	// Recherche de l'activité dont l'ID correspond
-	for _, activity := range result.Ocs.Data {
-		if activity.ActivityID == idInt {
-			return activity, nil
+	return *found, nil
+}
+
+// activityFilterID est la forme des identifiants de filtre de l'API Activity
+// (all, self, by, files, calendar_todo...)
+var activityFilterID = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// activityAppFilters associe une app au filtre de l'API Activity
+// (api/v2/activity/{filter}) qui restreint le flux à ses activités. Les autres
+// apps sont filtrées côté client pendant la pagination.
+var activityAppFilters = map[string]string{
+	"files":         "files",
+	"files_sharing": "files_sharing",
+	"comments":      "comments",
+	"deck":          "deck",
+}
+
+// forEachActivity appelle fn pour chaque activité du filtre (all, files...),
+// de la plus récente à la plus ancienne, page par page (curseur "since" et
+// en-tête X-Activity-Last-Given), jusqu'à ce que fn retourne false ou que
+// l'historique soit épuisé.
+func forEachActivity(ctx context.Context, client *NextcloudClient, filter string, fn func(Activity) bool) error {
+	endpoint := fmt.Sprintf("ocs/v2.php/apps/activity/api/v2/activity/%s?format=json&sort=desc&limit=%d", url.PathEscape(filter), activityPageSize)
+	return paginateOCS(ctx, client, endpoint, "since", "X-Activity-Last-Given", func(activities []Activity) bool {
+		for _, activity := range activities {
+			if !fn(activity) {
+				return false
+			}
 		}
-	}
-	
-	// Si aucune activité trouvée
-	return nil, fmt.Errorf("activity with ID %s not found", id)
-}
\ No newline at end of file
+		return true
+	})
+}
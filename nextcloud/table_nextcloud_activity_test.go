@@ -0,0 +1,91 @@
+package nextcloud
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// activityPages serves the activity stream in pages, newest first, linked by
+// the X-Activity-Last-Given cursor
+func activityPages(t *testing.T, requests *[]string) http.Handler {
+	pages := map[string]struct {
+		ids  []int64
+		last string
+	}{
+		"":   {ids: []int64{30, 29}, last: "29"},
+		"29": {ids: []int64{20, 19}, last: "19"},
+		"19": {ids: []int64{12, 11}, last: "11"},
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ocs/v2.php/apps/activity/api/v2/activity/all" {
+			http.NotFound(w, r)
+			return
+		}
+		since := r.URL.Query().Get("since")
+		*requests = append(*requests, since)
+		page, ok := pages[since]
+		if !ok {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		activities := make([]map[string]interface{}, 0, len(page.ids))
+		for _, id := range page.ids {
+			activities = append(activities, map[string]interface{}{
+				"activity_id": id,
+				"app":         "files",
+				"type":        "file_changed",
+				"subject":     "You changed a file",
+				"datetime":    "2024-05-01T10:00:00+00:00",
+				"user":        "admin",
+			})
+		}
+		w.Header().Set("X-Activity-Last-Given", page.last)
+		writeOCS(t, w, activities)
+	})
+}
+
+func TestGetActivityOnSecondPage(t *testing.T) {
+	var requests []string
+	_, conn := newTestServer(t, activityPages(t, &requests))
+	d := &plugin.QueryData{
+		Connection:  conn,
+		EqualsQuals: plugin.KeyColumnEqualsQualMap{"id": &proto.QualValue{Value: &proto.QualValue_StringValue{StringValue: "20"}}},
+	}
+
+	item, err := getActivity(context.Background(), d, nil)
+	if err != nil {
+		t.Fatalf("getActivity: %v", err)
+	}
+	activity, ok := item.(Activity)
+	if !ok {
+		t.Fatalf("got %T, want Activity", item)
+	}
+	if activity.ActivityID != 20 {
+		t.Errorf("got activity %d, want 20", activity.ActivityID)
+	}
+	// the first page and the page after its cursor, not the third one
+	if len(requests) != 2 || requests[0] != "" || requests[1] != "29" {
+		t.Errorf("got since cursors %q, want [\"\" \"29\"]", requests)
+	}
+}
+
+func TestGetActivityNotFound(t *testing.T) {
+	var requests []string
+	_, conn := newTestServer(t, activityPages(t, &requests))
+	d := &plugin.QueryData{
+		Connection:  conn,
+		EqualsQuals: plugin.KeyColumnEqualsQualMap{"id": &proto.QualValue{Value: &proto.QualValue_StringValue{StringValue: "25"}}},
+	}
+
+	if _, err := getActivity(context.Background(), d, nil); err == nil {
+		t.Fatal("expected an error for an activity missing from the stream")
+	}
+	// 25 falls between the first two pages: paging stops once it is passed
+	if len(requests) != 2 {
+		t.Errorf("got %d requests, want 2", len(requests))
+	}
+}
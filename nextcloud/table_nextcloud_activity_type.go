@@ -0,0 +1,115 @@
+package nextcloud
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// activityFilter is a filter of the Activity API (api/v2/activity/{filter})
+type activityFilter struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Icon     string `json:"icon"`
+	Priority int    `json:"priority"`
+	Source   string `json:"-"`
+}
+
+// staticActivityFilters is returned when the server does not expose its
+// filter list: the filters registered by the Activity app and the apps
+// shipped with Nextcloud, keyed by the major version that introduced them
+var staticActivityFilters = map[int][]activityFilter{
+	9: {
+		{ID: "all", Name: "All activities"},
+		{ID: "self", Name: "By you"},
+		{ID: "by", Name: "By others"},
+		{ID: "files", Name: "File changes"},
+		{ID: "files_favorites", Name: "Favorites"},
+		{ID: "files_sharing", Name: "File shares"},
+		{ID: "comments", Name: "Comments"},
+	},
+	12: {
+		{ID: "calendar", Name: "Calendar"},
+	},
+	14: {
+		{ID: "calendar_todo", Name: "Tasks"},
+		{ID: "contacts", Name: "Contacts"},
+	},
+}
+
+// staticActivityFiltersFor returns the static filters available on a server
+// of the given major version, every filter when the version is unknown (0)
+func staticActivityFiltersFor(major int) []activityFilter {
+	versions := make([]int, 0, len(staticActivityFilters))
+	for version := range staticActivityFilters {
+		if major == 0 || version <= major {
+			versions = append(versions, version)
+		}
+	}
+	slices.Sort(versions)
+
+	var filters []activityFilter
+	for _, version := range versions {
+		filters = append(filters, staticActivityFilters[version]...)
+	}
+	return filters
+}
+
+// tableNextcloudActivityType defines the schema for the catalog of activity filters
+func tableNextcloudActivityType() *plugin.Table {
+	return &plugin.Table{
+		Name:        "nextcloud_activity_type",
+		Description: "Activity filters available on the server, the valid values of the filter column of nextcloud_activity",
+		List: &plugin.ListConfig{
+			Hydrate: listIfAppEnabled("activity", listActivityFilters),
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "id", Type: proto.ColumnType_STRING, Description: "Filter ID, as used in api/v2/activity/{filter}", Transform: transform.FromField("ID")},
+			{Name: "name", Type: proto.ColumnType_STRING, Description: "Display name of the filter", Transform: transform.FromField("Name")},
+			{Name: "icon", Type: proto.ColumnType_STRING, Description: "URL of the icon of the filter", Transform: transform.FromField("Icon").NullIfZero()},
+			{Name: "priority", Type: proto.ColumnType_INT, Description: "Sort priority of the filter in the UI", Transform: transform.FromField("Priority").NullIfZero()},
+			{Name: "source", Type: proto.ColumnType_STRING, Description: "api when listed by the server, static when the server does not expose its filters (the filters shipped with its major version)", Transform: transform.FromField("Source")},
+		}),
+	}
+}
+
+// listActivityFilters streams the filters listed by the Activity API, or the static list when it is unavailable
+func listActivityFilters(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	filters, err := ocsGet[activityFilter](ctx, client, "ocs/v2.php/apps/activity/api/v2/activity/filters?format=json")
+	if errors.Is(err, ErrNotFound) {
+		plugin.Logger(ctx).Warn("listActivityFilters", "message", "filter list unavailable, returning the static list", "error", err)
+		major := 0
+		if caps, err := getCapabilities(ctx, d); err == nil {
+			major = caps.Version.Major
+		}
+		for _, filter := range staticActivityFiltersFor(major) {
+			filter.Source = "static"
+			d.StreamListItem(ctx, filter)
+			if d.RowsRemaining(ctx) == 0 {
+				return nil, nil
+			}
+		}
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, filter := range filters {
+		filter.Source = "api"
+		d.StreamListItem(ctx, filter)
+		if d.RowsRemaining(ctx) == 0 {
+			return nil, nil
+		}
+	}
+	return nil, nil
+}
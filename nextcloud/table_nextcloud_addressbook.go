@@ -0,0 +1,140 @@
+package nextcloud
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// addressbookPropfindBody lists the properties requested for every address book
+const addressbookPropfindBody = `<?xml version="1.0" encoding="utf-8"?>
+<d:propfind xmlns:d="DAV:" xmlns:card="urn:ietf:params:xml:ns:carddav" xmlns:cs="http://calendarserver.org/ns/" xmlns:oc="http://owncloud.org/ns">
+  <d:prop>
+    <d:resourcetype/>
+    <d:displayname/>
+    <d:owner/>
+    <d:current-user-privilege-set/>
+    <card:addressbook-description/>
+    <cs:getctag/>
+    <oc:owner-principal/>
+  </d:prop>
+</d:propfind>`
+
+// davAddressbookProp holds the WebDAV/CardDAV properties of an address book
+type davAddressbookProp struct {
+	ResourceType struct {
+		Addressbook *struct{} `xml:"urn:ietf:params:xml:ns:carddav addressbook"`
+	} `xml:"DAV: resourcetype"`
+	DisplayName string `xml:"DAV: displayname"`
+	Owner       struct {
+		Href string `xml:"DAV: href"`
+	} `xml:"DAV: owner"`
+	Privileges []struct {
+		Write        *struct{} `xml:"DAV: write"`
+		WriteContent *struct{} `xml:"DAV: write-content"`
+		All          *struct{} `xml:"DAV: all"`
+	} `xml:"DAV: current-user-privilege-set>privilege"`
+	Description    string `xml:"urn:ietf:params:xml:ns:carddav addressbook-description"`
+	CTag           string `xml:"http://calendarserver.org/ns/ getctag"`
+	OwnerPrincipal string `xml:"http://owncloud.org/ns owner-principal"`
+}
+
+// addressbook is a row of the nextcloud_addressbook table
+type addressbook struct {
+	Name        string
+	DisplayName string
+	Description string
+	CTag        string
+	Owner       string
+	ReadOnly    bool
+}
+
+// tableNextcloudAddressbook defines the schema for the address books of the configured user
+func tableNextcloudAddressbook() *plugin.Table {
+	return &plugin.Table{
+		Name:        "nextcloud_addressbook",
+		Description: "Address books (CardDAV) of the configured user, including the ones shared with them",
+		List: &plugin.ListConfig{
+			Hydrate: listAddressbooks,
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "name", Type: proto.ColumnType_STRING, Description: "URI name of the address book, as used by nextcloud_contact", Transform: transform.FromField("Name")},
+			{Name: "display_name", Type: proto.ColumnType_STRING, Description: "Display name of the address book", Transform: transform.FromField("DisplayName")},
+			{Name: "description", Type: proto.ColumnType_STRING, Description: "Description of the address book", Transform: transform.FromField("Description").NullIfZero()},
+			{Name: "ctag", Type: proto.ColumnType_STRING, Description: "Collection tag, changes whenever a contact changes", Transform: transform.FromField("CTag")},
+			{Name: "owner", Type: proto.ColumnType_STRING, Description: "User ID of the owner", Transform: transform.FromField("Owner")},
+			{Name: "read_only", Type: proto.ColumnType_BOOL, Description: "True if the configured user cannot modify the address book", Transform: transform.FromField("ReadOnly")},
+		}),
+	}
+}
+
+// listAddressbooks runs a Depth: 1 PROPFIND on the user's address book home
+func listAddressbooks(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf("remote.php/dav/addressbooks/users/%s/", url.PathEscape(client.Username))
+	err = davStream(ctx, client, "PROPFIND", endpoint, "1", addressbookPropfindBody, func(r davResponse[davAddressbookProp]) bool {
+		prop, ok := r.OKProp()
+		// the home collection itself is not an address book
+		if !ok || prop.ResourceType.Addressbook == nil {
+			return true
+		}
+		d.StreamListItem(ctx, addressbookFromDAV(r.Href, prop))
+		return d.RowsRemaining(ctx) != 0
+	})
+	if err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// addressbookFromDAV converts the PROPFIND properties of an address book into a row
+func addressbookFromDAV(href string, prop davAddressbookProp) addressbook {
+	name := path.Base(strings.TrimSuffix(href, "/"))
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		name = unescaped
+	}
+
+	owner := prop.OwnerPrincipal
+	if owner == "" {
+		owner = prop.Owner.Href
+	}
+
+	readOnly := len(prop.Privileges) > 0
+	for _, privilege := range prop.Privileges {
+		if privilege.Write != nil || privilege.WriteContent != nil || privilege.All != nil {
+			readOnly = false
+		}
+	}
+
+	return addressbook{
+		Name:        name,
+		DisplayName: prop.DisplayName,
+		Description: prop.Description,
+		CTag:        prop.CTag,
+		Owner:       principalUserID(owner),
+		ReadOnly:    readOnly,
+	}
+}
+
+// principalUserID extracts the user ID from a principal URI such as
+// principals/users/alice or /remote.php/dav/principals/users/alice/
+func principalUserID(principal string) string {
+	principal = strings.TrimSuffix(principal, "/")
+	if i := strings.LastIndex(principal, "principals/users/"); i >= 0 {
+		principal = principal[i+len("principals/users/"):]
+	}
+	if unescaped, err := url.PathUnescape(principal); err == nil {
+		return unescaped
+	}
+	return principal
+}
@@ -0,0 +1,130 @@
+package nextcloud
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// announcement represents an announcement of the AnnouncementCenter app
+type announcement struct {
+	ID       int64       `json:"id"`
+	AuthorID string      `json:"author_id"`
+	Author   string      `json:"author"`
+	Time     int64       `json:"time"`
+	Subject  string      `json:"subject"`
+	Message  string      `json:"message"`
+	Groups   interface{} `json:"groups"`
+	// Comments is the number of comments, or false when comments are disabled
+	Comments interface{} `json:"comments"`
+}
+
+// tableNextcloudAnnouncement defines the schema for the announcements of the AnnouncementCenter app
+func tableNextcloudAnnouncement() *plugin.Table {
+	return &plugin.Table{
+		Name:        "nextcloud_announcement",
+		Description: "Announcements from the Nextcloud AnnouncementCenter app visible to the configured user",
+		List: &plugin.ListConfig{
+			Hydrate: listIfAppEnabled("announcementcenter", listAnnouncements),
+		},
+		Get: &plugin.GetConfig{
+			KeyColumns: plugin.SingleColumn("id"),
+			Hydrate:    getAnnouncement,
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "id", Type: proto.ColumnType_INT, Description: "Announcement ID", Transform: transform.FromField("ID")},
+			{Name: "subject", Type: proto.ColumnType_STRING, Description: "Subject of the announcement", Transform: transform.FromField("Subject")},
+			{Name: "message", Type: proto.ColumnType_STRING, Description: "Message of the announcement", Transform: transform.FromField("Message")},
+			{Name: "author", Type: proto.ColumnType_STRING, Description: "User ID of the author", Transform: transform.FromField("AuthorID")},
+			{Name: "author_display_name", Type: proto.ColumnType_STRING, Description: "Display name of the author", Transform: transform.FromField("Author")},
+			{Name: "time", Type: proto.ColumnType_TIMESTAMP, Description: "Publication time of the announcement", Transform: transform.FromField("Time").Transform(transform.UnixToTimestamp)},
+			{Name: "groups", Type: proto.ColumnType_JSON, Description: "Groups the announcement was sent to (everyone when empty)", Transform: transform.FromField("Groups")},
+			{Name: "comments", Type: proto.ColumnType_INT, Description: "Number of comments, NULL when comments are disabled", Transform: transform.FromField("Comments").Transform(announcementComments)},
+		}),
+	}
+}
+
+// listAnnouncements streams every announcement, newest first
+func listAnnouncements(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	err = forEachAnnouncement(ctx, client, func(a announcement) bool {
+		d.StreamListItem(ctx, a)
+		return d.RowsRemaining(ctx) != 0
+	})
+	return nil, err
+}
+
+// getAnnouncement retrieves a single announcement by ID (the API has no per-announcement endpoint)
+func getAnnouncement(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	qual := d.EqualsQuals["id"]
+	if qual == nil {
+		return nil, fmt.Errorf("id qualifier not provided")
+	}
+	id := qual.GetInt64Value()
+
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	// announcements come newest (highest ID) first
+	var found *announcement
+	err = forEachAnnouncement(ctx, client, func(a announcement) bool {
+		if a.ID == id {
+			found = &a
+		}
+		return found == nil && a.ID > id
+	})
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, fmt.Errorf("announcement with ID %d not found", id)
+	}
+	return found, nil
+}
+
+// forEachAnnouncement calls fn for every announcement until it returns false.
+// The API returns a few announcements at a time; offset is the ID of the
+// oldest announcement already received.
+func forEachAnnouncement(ctx context.Context, client *NextcloudClient, fn func(announcement) bool) error {
+	offset := int64(0)
+	for {
+		endpoint := "ocs/v2.php/apps/announcementcenter/api/v1/announcements?format=json"
+		if offset > 0 {
+			endpoint += fmt.Sprintf("&offset=%d", offset)
+		}
+		page, err := ocsGet[announcement](ctx, client, endpoint)
+		if err != nil {
+			return appNotEnabledError("announcementcenter", err)
+		}
+		if len(page) == 0 {
+			return nil
+		}
+		for _, a := range page {
+			if !fn(a) {
+				return nil
+			}
+		}
+
+		next := page[len(page)-1].ID
+		if next == offset {
+			return nil
+		}
+		offset = next
+	}
+}
+
+// announcementComments returns the comment count, or nil when comments are disabled (false)
+func announcementComments(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	if count, ok := d.Value.(float64); ok {
+		return int64(count), nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,117 @@
+package nextcloud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// appConfigEndpoint is the base of the provisioning API app config endpoints
+const appConfigEndpoint = "ocs/v2.php/apps/provisioning_api/api/v1/config/apps"
+
+// sensitiveConfigKeyParts are the key fragments whose values are never returned
+var sensitiveConfigKeyParts = []string{"secret", "password", "token"}
+
+// appConfigValue is a row of the nextcloud_app_config table
+type appConfigValue struct {
+	App      string
+	Key      string
+	Value    string
+	Redacted bool
+}
+
+// appConfigData is the data member of the app config endpoints, which wrap their result in another "data"
+type appConfigData[T any] struct {
+	Data T `json:"data"`
+}
+
+// tableNextcloudAppConfig defines the schema for the app configuration values
+func tableNextcloudAppConfig() *plugin.Table {
+	return &plugin.Table{
+		Name:        "nextcloud_app_config",
+		Description: "Configuration keys and values of the Nextcloud apps (requires admin credentials)",
+		List: &plugin.ListConfig{
+			Hydrate: listAppConfig,
+			KeyColumns: plugin.KeyColumnSlice{
+				{Name: "app", Require: plugin.Optional},
+			},
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "app", Type: proto.ColumnType_STRING, Description: "App ID", Transform: transform.FromField("App")},
+			{Name: "key", Type: proto.ColumnType_STRING, Description: "Configuration key", Transform: transform.FromField("Key")},
+			{Name: "value", Type: proto.ColumnType_STRING, Description: "Configuration value (empty when redacted)", Transform: transform.FromField("Value")},
+			{Name: "redacted", Type: proto.ColumnType_BOOL, Description: "True if the value was withheld because the key looks sensitive (secret, password, token)", Transform: transform.FromField("Redacted")},
+		}),
+	}
+}
+
+// listAppConfig enumerates the apps (or the requested one), their keys, and fetches each value
+func listAppConfig(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	var apps []string
+	if qual := d.EqualsQuals["app"]; qual != nil {
+		apps = []string{qual.GetStringValue()}
+	} else {
+		data, err := ocsGetData[appConfigData[[]string]](ctx, client, appConfigEndpoint+"?format=json")
+		if err != nil {
+			return nil, err
+		}
+		apps = data.Data
+	}
+
+	for _, app := range apps {
+		endpoint := fmt.Sprintf("%s/%s?format=json", appConfigEndpoint, url.PathEscape(app))
+		keys, err := ocsGetData[appConfigData[[]string]](ctx, client, endpoint)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range keys.Data {
+			row := appConfigValue{App: app, Key: key, Redacted: isSensitiveConfigKey(key)}
+			if !row.Redacted {
+				endpoint := fmt.Sprintf("%s/%s/%s?format=json", appConfigEndpoint, url.PathEscape(app), url.PathEscape(key))
+				value, err := ocsGetData[appConfigData[json.RawMessage]](ctx, client, endpoint)
+				if err != nil {
+					return nil, err
+				}
+				row.Value = rawConfigValue(value.Data)
+			}
+
+			d.StreamListItem(ctx, row)
+			if d.RowsRemaining(ctx) == 0 {
+				return nil, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// isSensitiveConfigKey reports whether the value of key must not be returned
+func isSensitiveConfigKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, part := range sensitiveConfigKeyParts {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// rawConfigValue returns a config value as text: strings unquoted, other JSON values as is
+func rawConfigValue(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	return string(raw)
+}
@@ -0,0 +1,74 @@
+package nextcloud
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// tableNextcloudAppStore defines the schema for the app store listing as seen by the server
+func tableNextcloudAppStore() *plugin.Table {
+	return &plugin.Table{
+		Name:        "nextcloud_app_store",
+		Description: "Apps of the app store compatible with the server, with their rating and latest version, fetched by the server from the app store (requires admin credentials). The app store does not publish download counts",
+		List: &plugin.ListConfig{
+			Hydrate: listAppStore,
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "app_id", Type: proto.ColumnType_STRING, Description: "App ID", Transform: transform.FromField("ID")},
+			{Name: "name", Type: proto.ColumnType_STRING, Description: "Name of the app", Transform: transform.FromField("Name")},
+			{Name: "summary", Type: proto.ColumnType_STRING, Description: "Short description of the app", Transform: transform.FromField("Summary").NullIfZero()},
+			{Name: "category", Type: proto.ColumnType_JSON, Description: "App store categories of the app", Transform: transform.FromMethod("Categories")},
+			{Name: "rating", Type: proto.ColumnType_DOUBLE, Description: "Average rating of the app, from 0 (worst) to 1 (best)", Transform: transform.FromField("Score")},
+			{Name: "rating_count", Type: proto.ColumnType_INT, Description: "Number of ratings of the app", Transform: transform.FromField("RatingNumOverall")},
+			{Name: "latest_version", Type: proto.ColumnType_STRING, Description: "Newest version of the app compatible with the server", Transform: transform.FromMethod("LatestVersion")},
+			{Name: "installed_version", Type: proto.ColumnType_STRING, Description: "Installed version, NULL when the app is not installed", Transform: transform.From(installedAppVersion)},
+			{Name: "enabled", Type: proto.ColumnType_BOOL, Description: "True if the app is installed and enabled", Transform: transform.FromField("Active")},
+		}),
+	}
+}
+
+// listAppStore streams the apps of the apps management listing that come from the app store
+func listAppStore(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	var listing struct {
+		Apps []appListEntry `json:"apps"`
+	}
+	if err := client.GetJSON(ctx, "index.php/settings/apps/list", &listing); err != nil {
+		return nil, fmt.Errorf("unable to read the apps listing of the server (admin credentials are required): %w", err)
+	}
+
+	// Without the app store (appstoreenabled = false, or no outgoing internet
+	// access) the listing only holds the installed apps
+	fromStore := 0
+	for _, app := range listing.Apps {
+		if !app.AppStore {
+			continue
+		}
+		fromStore++
+		d.StreamListItem(ctx, app)
+		if d.RowsRemaining(ctx) == 0 {
+			return nil, nil
+		}
+	}
+	if fromStore == 0 {
+		return nil, fmt.Errorf("the app store is offline for this server: it is disabled (appstoreenabled) or the server cannot reach apps.nextcloud.com")
+	}
+	return nil, nil
+}
+
+// installedAppVersion returns the version of an installed app, nil otherwise
+func installedAppVersion(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	app, ok := d.HydrateItem.(appListEntry)
+	if !ok || !app.Installed {
+		return nil, nil
+	}
+	return app.Version, nil
+}
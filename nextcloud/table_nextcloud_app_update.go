@@ -0,0 +1,109 @@
+package nextcloud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// appListEntry is an app of the apps management listing, with the version
+// available in the app store when there is an update
+type appListEntry struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Update  string `json:"update"`
+
+	// App store metadata, absent for apps the store does not list
+	Summary          string          `json:"summary"`
+	Category         json.RawMessage `json:"category"`
+	Score            float64         `json:"score"`
+	RatingNumOverall int64           `json:"ratingNumOverall"`
+	AppStore         bool            `json:"appstore"`
+	Installed        bool            `json:"installed"`
+	Active           bool            `json:"active"`
+}
+
+// Categories returns the app store categories of the app, sent as a list or,
+// for some apps, a single string
+func (a appListEntry) Categories() []string {
+	var categories []string
+	if json.Unmarshal(a.Category, &categories) == nil {
+		return categories
+	}
+	var category string
+	if json.Unmarshal(a.Category, &category) == nil && category != "" {
+		return []string{category}
+	}
+	return nil
+}
+
+// LatestVersion returns the newest version of the app: the update when there
+// is one, otherwise the listed version
+func (a appListEntry) LatestVersion() string {
+	if a.Update != "" {
+		return a.Update
+	}
+	return a.Version
+}
+
+// tableNextcloudAppUpdate defines the schema for the enabled apps with an update available
+func tableNextcloudAppUpdate() *plugin.Table {
+	return &plugin.Table{
+		Name:        "nextcloud_app_update",
+		Description: "Enabled Nextcloud apps with a newer version available in the app store (requires admin credentials)",
+		List: &plugin.ListConfig{
+			Hydrate: listAppUpdates,
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "app_id", Type: proto.ColumnType_STRING, Description: "App ID", Transform: transform.FromField("ID")},
+			{Name: "name", Type: proto.ColumnType_STRING, Description: "Name of the app", Transform: transform.FromField("Name")},
+			{Name: "current_version", Type: proto.ColumnType_STRING, Description: "Installed version", Transform: transform.FromField("Version")},
+			{Name: "available_version", Type: proto.ColumnType_STRING, Description: "Newest version available in the app store", Transform: transform.FromField("Update")},
+		}),
+	}
+}
+
+// listAppUpdates streams the enabled apps whose app store listing carries an update
+func listAppUpdates(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	enabled, err := ocsGetData[struct {
+		Apps []string `json:"apps"`
+	}](ctx, client, "ocs/v2.php/cloud/apps?filter=enabled&format=json")
+	if err != nil {
+		return nil, err
+	}
+	isEnabled := map[string]bool{}
+	for _, id := range enabled.Apps {
+		isEnabled[id] = true
+	}
+
+	// The update metadata comes from the app store through the apps management
+	// listing. When the app store is disabled or unreachable the listing still
+	// answers, with the installed apps and no update field: no rows
+	var listing struct {
+		Apps []appListEntry `json:"apps"`
+	}
+	if err := client.GetJSON(ctx, "index.php/settings/apps/list", &listing); err != nil {
+		return nil, fmt.Errorf("unable to read the apps listing of the server (admin credentials are required): %w", err)
+	}
+
+	for _, app := range listing.Apps {
+		if app.Update == "" || !isEnabled[app.ID] {
+			continue
+		}
+		d.StreamListItem(ctx, app)
+		if d.RowsRemaining(ctx) == 0 {
+			return nil, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,99 @@
+package nextcloud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// bookmarkPageSize is the number of bookmarks requested per page
+const bookmarkPageSize = 100
+
+// bookmark represents a bookmark from the Bookmarks app
+type bookmark struct {
+	ID          int64    `json:"id"`
+	URL         string   `json:"url"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+	Added       int64    `json:"added"`
+	ClickCount  int64    `json:"clickcount"`
+}
+
+// bookmarkListResponse is the envelope of the Bookmarks API, which is not OCS.
+// On errors, data holds the error messages instead of bookmarks.
+type bookmarkListResponse struct {
+	Status string          `json:"status"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// tableNextcloudBookmark defines the schema for the bookmarks of the configured user
+func tableNextcloudBookmark() *plugin.Table {
+	return &plugin.Table{
+		Name:        "nextcloud_bookmark",
+		Description: "Bookmarks of the configured user from the Nextcloud Bookmarks app",
+		List: &plugin.ListConfig{
+			Hydrate: listIfAppEnabled("bookmarks", listBookmarks),
+			KeyColumns: plugin.KeyColumnSlice{
+				{Name: "tag", Require: plugin.Optional},
+			},
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "id", Type: proto.ColumnType_INT, Description: "Bookmark ID", Transform: transform.FromField("ID")},
+			{Name: "url", Type: proto.ColumnType_STRING, Description: "Bookmarked URL", Transform: transform.FromField("URL")},
+			{Name: "title", Type: proto.ColumnType_STRING, Description: "Title of the bookmark", Transform: transform.FromField("Title")},
+			{Name: "description", Type: proto.ColumnType_STRING, Description: "Description of the bookmark", Transform: transform.FromField("Description").NullIfZero()},
+			{Name: "tags", Type: proto.ColumnType_JSON, Description: "Tags of the bookmark", Transform: transform.FromField("Tags")},
+			{Name: "added", Type: proto.ColumnType_TIMESTAMP, Description: "Time the bookmark was added", Transform: transform.FromField("Added").Transform(transform.UnixToTimestamp)},
+			{Name: "clickcount", Type: proto.ColumnType_INT, Description: "Number of times the bookmark was clicked", Transform: transform.FromField("ClickCount")},
+			{Name: "tag", Type: proto.ColumnType_STRING, Description: "Tag to filter bookmarks on", Transform: transform.FromQual("tag")},
+		}),
+	}
+}
+
+// listBookmarks walks the pages of the Bookmarks API
+func listBookmarks(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("limit", strconv.Itoa(bookmarkPageSize))
+	if qual := d.EqualsQuals["tag"]; qual != nil {
+		params.Set("tags[]", qual.GetStringValue())
+	}
+
+	for page := 0; ; page++ {
+		params.Set("page", strconv.Itoa(page))
+		endpoint := "index.php/apps/bookmarks/public/rest/v2/bookmark?" + params.Encode()
+
+		var result bookmarkListResponse
+		if err := client.GetJSON(ctx, endpoint, &result); err != nil {
+			return nil, appNotEnabledError("bookmarks", err)
+		}
+		if result.Status != "success" {
+			return nil, fmt.Errorf("Bookmarks API error: %s", string(result.Data))
+		}
+		var bookmarks []bookmark
+		if err := json.Unmarshal(result.Data, &bookmarks); err != nil {
+			return nil, fmt.Errorf("%w JSON Nextcloud Bookmarks: %w", ErrDecode, err)
+		}
+
+		for _, b := range bookmarks {
+			d.StreamListItem(ctx, b)
+			if d.RowsRemaining(ctx) == 0 {
+				return nil, nil
+			}
+		}
+		if len(bookmarks) < bookmarkPageSize {
+			return nil, nil
+		}
+	}
+}
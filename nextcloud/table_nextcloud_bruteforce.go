@@ -0,0 +1,67 @@
+package nextcloud
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// bruteforceState is the brute-force protection state of the client address
+// making the request, published in the "bruteforce" capability
+type bruteforceState struct {
+	DelayMS     int64 `json:"delay"`
+	AllowListed bool  `json:"allow-listed"`
+}
+
+// DelaySeconds returns the throttling delay in seconds
+func (s bruteforceState) DelaySeconds() float64 {
+	return float64(s.DelayMS) / 1000
+}
+
+// Throttled reports whether requests from the address are currently slowed down
+func (s bruteforceState) Throttled() bool {
+	return s.DelayMS > 0
+}
+
+// tableNextcloudBruteforce defines the schema for the brute-force protection state (single row)
+func tableNextcloudBruteforce() *plugin.Table {
+	return &plugin.Table{
+		Name:        "nextcloud_bruteforce",
+		Description: "Brute-force protection state of the address Steampipe connects from (Nextcloud 24 or later). Nextcloud has no API listing every throttled address",
+		List: &plugin.ListConfig{
+			Hydrate: listBruteforceState,
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "throttled", Type: proto.ColumnType_BOOL, Description: "True if requests from this address are currently delayed", Transform: transform.FromMethod("Throttled")},
+			{Name: "delay_seconds", Type: proto.ColumnType_DOUBLE, Description: "Delay currently applied to login attempts from this address, in seconds", Transform: transform.FromMethod("DelaySeconds")},
+			{Name: "allow_listed", Type: proto.ColumnType_BOOL, Description: "True if the address is exempted from brute-force protection", Transform: transform.FromField("AllowListed")},
+		}),
+	}
+}
+
+// listBruteforceState reads the bruteforce capability, fetched fresh since the delay changes quickly
+func listBruteforceState(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	caps, err := ocsGetData[struct {
+		Version      serverVersion `json:"version"`
+		Capabilities struct {
+			Bruteforce *bruteforceState `json:"bruteforce"`
+		} `json:"capabilities"`
+	}](ctx, client, "ocs/v1.php/cloud/capabilities?format=json")
+	if err != nil {
+		return nil, err
+	}
+	if caps.Capabilities.Bruteforce == nil {
+		return nil, fmt.Errorf("brute-force protection state is not exposed by Nextcloud %s (requires Nextcloud 24 or later)", caps.Version.String)
+	}
+
+	d.StreamListItem(ctx, *caps.Capabilities.Bruteforce)
+	return nil, nil
+}
@@ -0,0 +1,69 @@
+package nextcloud
+
+import (
+	"context"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// capabilityCheck is the row of nextcloud_capability_check: well-known
+// feature flags extracted from the nested capabilities
+type capabilityCheck struct {
+	Version                       string
+	FilesSharingAPIEnabled        bool
+	FilesSharingPublicEnabled     bool
+	FilesSharingPasswordEnforced  bool
+	FilesSharingExpireDateEnforce bool
+	FilesSharingFederationOut     bool
+	FilesSharingFederationIn      bool
+	FilesVersioningEnabled        bool
+	EndToEndEncryptionEnabled     bool
+	Capabilities                  map[string]interface{}
+}
+
+// tableNextcloudCapabilityCheck defines the schema for the feature flags of the server (single row)
+func tableNextcloudCapabilityCheck() *plugin.Table {
+	return &plugin.Table{
+		Name:        "nextcloud_capability_check",
+		Description: "Well-known feature flags of the server as boolean columns, extracted from the capabilities (false when the capability is absent)",
+		List: &plugin.ListConfig{
+			Hydrate: listCapabilityCheck,
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "version", Type: proto.ColumnType_STRING, Description: "Version of the server", Transform: transform.FromField("Version")},
+			{Name: "files_sharing_api_enabled", Type: proto.ColumnType_BOOL, Description: "True if the sharing API is enabled", Transform: transform.FromField("FilesSharingAPIEnabled")},
+			{Name: "files_sharing_public_enabled", Type: proto.ColumnType_BOOL, Description: "True if users can share by public link", Transform: transform.FromField("FilesSharingPublicEnabled")},
+			{Name: "files_sharing_password_enforced", Type: proto.ColumnType_BOOL, Description: "True if public links must be protected by a password", Transform: transform.FromField("FilesSharingPasswordEnforced")},
+			{Name: "files_sharing_expire_date_enforced", Type: proto.ColumnType_BOOL, Description: "True if public links must have an expiration date", Transform: transform.FromField("FilesSharingExpireDateEnforce")},
+			{Name: "files_sharing_federation_outgoing", Type: proto.ColumnType_BOOL, Description: "True if users can share with users of other servers", Transform: transform.FromField("FilesSharingFederationOut")},
+			{Name: "files_sharing_federation_incoming", Type: proto.ColumnType_BOOL, Description: "True if users can receive shares from other servers", Transform: transform.FromField("FilesSharingFederationIn")},
+			{Name: "files_versioning_enabled", Type: proto.ColumnType_BOOL, Description: "True if file versions are kept (files_versions app)", Transform: transform.FromField("FilesVersioningEnabled")},
+			{Name: "end_to_end_encryption_enabled", Type: proto.ColumnType_BOOL, Description: "True if end-to-end encryption is available", Transform: transform.FromField("EndToEndEncryptionEnabled")},
+			{Name: "capabilities", Type: proto.ColumnType_JSON, Description: "Full capabilities, for the flags without a dedicated column", Transform: transform.FromField("Capabilities")},
+		}),
+	}
+}
+
+// listCapabilityCheck extracts the feature flags from the cached capabilities
+func listCapabilityCheck(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	caps, err := getCapabilities(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	d.StreamListItem(ctx, capabilityCheck{
+		Version:                       caps.Version.String,
+		FilesSharingAPIEnabled:        caps.Bool("files_sharing", "api_enabled"),
+		FilesSharingPublicEnabled:     caps.Bool("files_sharing", "public", "enabled"),
+		FilesSharingPasswordEnforced:  caps.Bool("files_sharing", "public", "password", "enforced"),
+		FilesSharingExpireDateEnforce: caps.Bool("files_sharing", "public", "expire_date", "enforced"),
+		FilesSharingFederationOut:     caps.Bool("files_sharing", "federation", "outgoing"),
+		FilesSharingFederationIn:      caps.Bool("files_sharing", "federation", "incoming"),
+		FilesVersioningEnabled:        caps.Bool("files", "versioning"),
+		EndToEndEncryptionEnabled:     caps.Bool("end-to-end-encryption", "enabled"),
+		Capabilities:                  caps.Capabilities,
+	})
+	return nil, nil
+}
@@ -0,0 +1,184 @@
+package nextcloud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// circle represents a circle (team) from the Circles app
+type circle struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+	Description string `json:"description"`
+	Source      int    `json:"source"`
+	Population  int    `json:"population"`
+	Config      int    `json:"config"`
+	Owner       struct {
+		UserID      string `json:"userId"`
+		DisplayName string `json:"displayName"`
+	} `json:"owner"`
+}
+
+// tableNextcloudCircle defines the schema for circles (teams)
+func tableNextcloudCircle() *plugin.Table {
+	return &plugin.Table{
+		Name:        "nextcloud_circle",
+		Description: "Circles (teams) from the Nextcloud Circles app visible to the configured user",
+		List: &plugin.ListConfig{
+			Hydrate: listIfAppEnabled("circles", listCircles),
+		},
+		Get: &plugin.GetConfig{
+			KeyColumns: plugin.SingleColumn("id"),
+			Hydrate:    getCircle,
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "id", Type: proto.ColumnType_STRING, Description: "Circle ID", Transform: transform.FromField("ID")},
+			{Name: "name", Type: proto.ColumnType_STRING, Description: "Name of the circle", Transform: transform.FromField("Name")},
+			{Name: "display_name", Type: proto.ColumnType_STRING, Description: "Display name of the circle", Transform: transform.FromField("DisplayName")},
+			{Name: "description", Type: proto.ColumnType_STRING, Description: "Description of the circle", Transform: transform.FromField("Description").NullIfZero()},
+			{Name: "type", Type: proto.ColumnType_INT, Description: "Source type of the circle (e.g. 1=user circle, 2=group, 16=circle)", Transform: transform.FromField("Source")},
+			{Name: "member_count", Type: proto.ColumnType_INT, Description: "Number of members of the circle", Transform: transform.FromField("Population")},
+			{Name: "owner", Type: proto.ColumnType_STRING, Description: "User ID of the owner of the circle", Transform: transform.FromField("Owner.UserID").NullIfZero()},
+			{Name: "owner_display_name", Type: proto.ColumnType_STRING, Description: "Display name of the owner of the circle", Transform: transform.FromField("Owner.DisplayName").NullIfZero()},
+			{Name: "config", Type: proto.ColumnType_INT, Description: "Configuration bitmask of the circle (visibility, open, invite...)", Transform: transform.FromField("Config")},
+		}),
+	}
+}
+
+// listCircles streams every circle visible to the configured user
+func listCircles(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	circles, err := ocsGet[circle](ctx, client, "ocs/v2.php/apps/circles/circles?format=json")
+	if err != nil {
+		return nil, appNotEnabledError("circles", err)
+	}
+	for _, c := range circles {
+		d.StreamListItem(ctx, c)
+	}
+	return nil, nil
+}
+
+// getCircle retrieves a single circle by ID
+func getCircle(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	qual := d.EqualsQuals["id"]
+	if qual == nil {
+		return nil, fmt.Errorf("id qualifier not provided")
+	}
+	id := qual.GetStringValue()
+
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	c, err := fetchCircle(ctx, client, id)
+	if errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("circle with ID %s not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// fetchCircle retrieves the circle with the given ID. A 404 is returned as
+// is: on this route it means the circle is unknown, not that the app is off.
+func fetchCircle(ctx context.Context, client *NextcloudClient, id string) (*circle, error) {
+	endpoint := fmt.Sprintf("ocs/v2.php/apps/circles/circles/%s?format=json", url.PathEscape(id))
+	c, err := ocsGetData[circle](ctx, client, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Circle member types as returned by the Circles app
+const (
+	circleMemberTypeUser  = 1
+	circleMemberTypeGroup = 2
+)
+
+// circleMember is a direct member of a circle
+type circleMember struct {
+	UserID   string `json:"userId"`
+	UserType int    `json:"userType"`
+	Status   string `json:"status"`
+}
+
+// circleMemberIDs returns the user IDs of the members of a circle, the groups
+// added to it being resolved to their members. Only direct user and group
+// members are resolved: e-mail addresses, contacts and nested circles have no
+// user ID. Kept in the connection cache like groupMemberIDs.
+func circleMemberIDs(ctx context.Context, d *plugin.QueryData, client *NextcloudClient, id string) ([]string, error) {
+	cacheKey := "nextcloud_circle_members:" + id
+	if cached, ok := d.ConnectionCache.Get(ctx, cacheKey); ok {
+		return cached.([]string), nil
+	}
+
+	endpoint := fmt.Sprintf("ocs/v2.php/apps/circles/circles/%s/members?format=json", url.PathEscape(id))
+	members, err := ocsGet[circleMember](ctx, client, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, member := range members {
+		// invited or requesting members have no access yet
+		if member.Status != "" && member.Status != "Member" {
+			continue
+		}
+		switch member.UserType {
+		case circleMemberTypeUser:
+			ids = append(ids, member.UserID)
+		case circleMemberTypeGroup:
+			groupIDs, err := groupMemberIDs(ctx, d, client, member.UserID)
+			if err != nil {
+				return nil, err
+			}
+			ids = append(ids, groupIDs...)
+		}
+	}
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	if err := d.ConnectionCache.SetWithTTL(ctx, cacheKey, ids, membersCacheTTL); err != nil {
+		plugin.Logger(ctx).Warn("circleMemberIDs", "cache_error", err)
+	}
+	return ids, nil
+}
+
+// circleNameCacheTTL is how long the name of a circle is reused from the connection cache
+const circleNameCacheTTL = 5 * time.Minute
+
+// circleName returns the display name of a circle, kept in the connection
+// cache so that the shares of a same circle cost a single request
+func circleName(ctx context.Context, d *plugin.QueryData, client *NextcloudClient, id string) (string, error) {
+	cacheKey := "nextcloud_circle_name:" + id
+	if cached, ok := d.ConnectionCache.Get(ctx, cacheKey); ok {
+		return cached.(string), nil
+	}
+
+	c, err := fetchCircle(ctx, client, id)
+	if err != nil {
+		return "", err
+	}
+	name := c.DisplayName
+	if name == "" {
+		name = c.Name
+	}
+	if err := d.ConnectionCache.SetWithTTL(ctx, cacheKey, name, circleNameCacheTTL); err != nil {
+		plugin.Logger(ctx).Warn("circleName", "cache_error", err)
+	}
+	return name, nil
+}
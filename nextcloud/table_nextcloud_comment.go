@@ -0,0 +1,113 @@
+package nextcloud
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// commentPageSize is the number of comments requested per REPORT
+const commentPageSize = 100
+
+// davCommentProp holds the oc: properties of a file comment
+type davCommentProp struct {
+	ID               string `xml:"http://owncloud.org/ns id"`
+	ActorID          string `xml:"http://owncloud.org/ns actorId"`
+	ActorDisplayName string `xml:"http://owncloud.org/ns actorDisplayName"`
+	ActorType        string `xml:"http://owncloud.org/ns actorType"`
+	Message          string `xml:"http://owncloud.org/ns message"`
+	CreationDateTime string `xml:"http://owncloud.org/ns creationDateTime"`
+	Verb             string `xml:"http://owncloud.org/ns verb"`
+	ObjectID         string `xml:"http://owncloud.org/ns objectId"`
+}
+
+// fileComment is a row of the nextcloud_comment table
+type fileComment struct {
+	FileID           string
+	ID               string
+	ActorID          string
+	ActorDisplayName string
+	ActorType        string
+	Message          string
+	CreationDateTime time.Time
+	Verb             string
+}
+
+// tableNextcloudComment defines the schema for comments on files
+func tableNextcloudComment() *plugin.Table {
+	return &plugin.Table{
+		Name:        "nextcloud_comment",
+		Description: "Comments on a Nextcloud file (requires a file_id qualifier)",
+		List: &plugin.ListConfig{
+			Hydrate:    listComments,
+			KeyColumns: plugin.SingleColumn("file_id"),
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "file_id", Type: proto.ColumnType_STRING, Description: "ID of the commented file", Transform: transform.FromField("FileID")},
+			{Name: "id", Type: proto.ColumnType_STRING, Description: "Comment ID", Transform: transform.FromField("ID")},
+			{Name: "actor_id", Type: proto.ColumnType_STRING, Description: "ID of the comment author", Transform: transform.FromField("ActorID")},
+			{Name: "actor_display_name", Type: proto.ColumnType_STRING, Description: "Display name of the comment author", Transform: transform.FromField("ActorDisplayName")},
+			{Name: "actor_type", Type: proto.ColumnType_STRING, Description: "Type of the author (users, guests...)", Transform: transform.FromField("ActorType")},
+			{Name: "message", Type: proto.ColumnType_STRING, Description: "Comment message", Transform: transform.FromField("Message")},
+			{Name: "creation_datetime", Type: proto.ColumnType_TIMESTAMP, Description: "Creation time of the comment", Transform: transform.FromField("CreationDateTime").NullIfZero()},
+			{Name: "verb", Type: proto.ColumnType_STRING, Description: "Verb of the comment (comment, system...)", Transform: transform.FromField("Verb")},
+		}),
+	}
+}
+
+// listComments runs paginated REPORT requests against the comments collection of a file
+func listComments(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	fileID := d.EqualsQuals["file_id"].GetStringValue()
+	if fileID == "" {
+		return nil, fmt.Errorf("file_id qualifier not provided")
+	}
+
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := fmt.Sprintf("remote.php/dav/comments/files/%s", url.PathEscape(fileID))
+
+	for offset := 0; ; offset += commentPageSize {
+		body := fmt.Sprintf(`<?xml version="1.0" encoding="utf-8"?>
+<oc:filter-comments xmlns:d="DAV:" xmlns:oc="http://owncloud.org/ns">
+  <oc:limit>%d</oc:limit>
+  <oc:offset>%d</oc:offset>
+</oc:filter-comments>`, commentPageSize, offset)
+
+		responses, err := davQuery[davCommentProp](ctx, client, "REPORT", endpoint, "", body)
+		if err != nil {
+			return nil, err
+		}
+
+		count := 0
+		for _, r := range responses {
+			prop, ok := r.OKProp()
+			if !ok || prop.ID == "" {
+				continue
+			}
+			count++
+			d.StreamListItem(ctx, fileComment{
+				FileID:           fileID,
+				ID:               prop.ID,
+				ActorID:          prop.ActorID,
+				ActorDisplayName: prop.ActorDisplayName,
+				ActorType:        prop.ActorType,
+				Message:          prop.Message,
+				CreationDateTime: parseDAVTime(prop.CreationDateTime),
+				Verb:             prop.Verb,
+			})
+			if d.RowsRemaining(ctx) == 0 {
+				return nil, nil
+			}
+		}
+		if count < commentPageSize {
+			return nil, nil
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package nextcloud
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// contactReportBody is the addressbook-query REPORT returning the vCard of every contact
+const contactReportBody = `<?xml version="1.0" encoding="utf-8"?>
+<card:addressbook-query xmlns:d="DAV:" xmlns:card="urn:ietf:params:xml:ns:carddav">
+  <d:prop>
+    <d:getetag/>
+    <card:address-data/>
+  </d:prop>
+</card:addressbook-query>`
+
+// davContactProp holds the properties returned for a contact
+type davContactProp struct {
+	ETag        string `xml:"DAV: getetag"`
+	AddressData string `xml:"urn:ietf:params:xml:ns:carddav address-data"`
+}
+
+// contactValue is a typed value of a contact (email address, phone number)
+type contactValue struct {
+	Value string   `json:"value"`
+	Types []string `json:"types,omitempty"`
+}
+
+// contact is a row of the nextcloud_contact table
+type contact struct {
+	Addressbook  string
+	UID          string
+	FullName     string
+	Emails       []contactValue
+	Phones       []contactValue
+	Organization string
+	Categories   []string
+	ETag         string
+}
+
+// tableNextcloudContact defines the schema for the contacts of an address book
+func tableNextcloudContact() *plugin.Table {
+	return &plugin.Table{
+		Name:        "nextcloud_contact",
+		Description: "Contacts (CardDAV) of an address book of the configured user (requires an addressbook qualifier)",
+		List: &plugin.ListConfig{
+			Hydrate:    listContacts,
+			KeyColumns: plugin.SingleColumn("addressbook"),
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "addressbook", Type: proto.ColumnType_STRING, Description: "URI name of the address book (see nextcloud_addressbook.name)", Transform: transform.FromField("Addressbook")},
+			{Name: "uid", Type: proto.ColumnType_STRING, Description: "Unique ID of the contact", Transform: transform.FromField("UID")},
+			{Name: "full_name", Type: proto.ColumnType_STRING, Description: "Formatted name of the contact", Transform: transform.FromField("FullName")},
+			{Name: "emails", Type: proto.ColumnType_JSON, Description: "Email addresses with their types", Transform: transform.FromField("Emails")},
+			{Name: "phones", Type: proto.ColumnType_JSON, Description: "Phone numbers with their types", Transform: transform.FromField("Phones")},
+			{Name: "organization", Type: proto.ColumnType_STRING, Description: "Organization name followed by its units, comma separated", Transform: transform.FromField("Organization").NullIfZero()},
+			{Name: "categories", Type: proto.ColumnType_JSON, Description: "Categories (groups) of the contact", Transform: transform.FromField("Categories")},
+			{Name: "etag", Type: proto.ColumnType_STRING, Description: "ETag of the vCard", Transform: transform.FromField("ETag")},
+		}),
+	}
+}
+
+// listContacts runs an addressbook-query REPORT and parses the returned vCards
+func listContacts(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	book := d.EqualsQuals["addressbook"].GetStringValue()
+	if book == "" {
+		return nil, fmt.Errorf("addressbook qualifier not provided")
+	}
+
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf("remote.php/dav/addressbooks/users/%s/%s/", url.PathEscape(client.Username), url.PathEscape(book))
+	err = davStream(ctx, client, "REPORT", endpoint, "1", contactReportBody, func(r davResponse[davContactProp]) bool {
+		prop, ok := r.OKProp()
+		if !ok || prop.AddressData == "" {
+			return true
+		}
+		row := contactFromVCard(parseVCard(prop.AddressData))
+		row.Addressbook = book
+		row.ETag = strings.Trim(prop.ETag, `"`)
+		d.StreamListItem(ctx, row)
+		return d.RowsRemaining(ctx) != 0
+	})
+	if err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// contactFromVCard maps the vCard properties to the contact columns
+func contactFromVCard(card vcard) contact {
+	row := contact{
+		UID:      vcardUnescape(card.First("UID")),
+		FullName: vcardUnescape(card.First("FN")),
+	}
+	// ORG is structured: organization name, then units
+	var org []string
+	for _, part := range vcardSplit(card.First("ORG"), ';') {
+		if part != "" {
+			org = append(org, part)
+		}
+	}
+	row.Organization = strings.Join(org, ", ")
+	for _, prop := range card["EMAIL"] {
+		row.Emails = append(row.Emails, contactValue{Value: vcardUnescape(prop.Value), Types: prop.Params["TYPE"]})
+	}
+	for _, prop := range card["TEL"] {
+		row.Phones = append(row.Phones, contactValue{Value: strings.TrimPrefix(vcardUnescape(prop.Value), "tel:"), Types: prop.Params["TYPE"]})
+	}
+	for _, prop := range card["CATEGORIES"] {
+		row.Categories = append(row.Categories, vcardSplit(prop.Value, ',')...)
+	}
+	return row
+}
@@ -0,0 +1,98 @@
+package nextcloud
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// trustedServer represents a server trusted through the Federation app
+type trustedServer struct {
+	ID     int64  `json:"id"`
+	URL    string `json:"url"`
+	Status int    `json:"status"`
+}
+
+// tableNextcloudFederatedServer defines the schema for the trusted servers of the Federation app
+func tableNextcloudFederatedServer() *plugin.Table {
+	return &plugin.Table{
+		Name:        "nextcloud_federated_server",
+		Description: "Trusted servers configured in the Nextcloud Federation app (requires admin credentials)",
+		List: &plugin.ListConfig{
+			Hydrate: listIfAppEnabled("federation", listFederatedServers),
+		},
+		Get: &plugin.GetConfig{
+			KeyColumns: plugin.SingleColumn("id"),
+			Hydrate:    getFederatedServer,
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "id", Type: proto.ColumnType_INT, Description: "Trusted server ID", Transform: transform.FromField("ID")},
+			{Name: "url", Type: proto.ColumnType_STRING, Description: "URL of the trusted server", Transform: transform.FromField("URL")},
+			{Name: "status", Type: proto.ColumnType_STRING, Description: "Status of the trust (ok, pending, failure, access_revoked)", Transform: transform.FromField("Status").Transform(trustedServerStatusName)},
+		}),
+	}
+}
+
+// fetchTrustedServers retrieves every trusted server
+func fetchTrustedServers(ctx context.Context, d *plugin.QueryData) ([]trustedServer, error) {
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	servers, err := ocsGet[trustedServer](ctx, client, "ocs/v2.php/apps/federation/api/v1/trusted-servers?format=json")
+	return servers, appNotEnabledError("federation", err)
+}
+
+// listFederatedServers streams every trusted server
+func listFederatedServers(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	servers, err := fetchTrustedServers(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	for _, server := range servers {
+		d.StreamListItem(ctx, server)
+	}
+	return nil, nil
+}
+
+// getFederatedServer retrieves a single trusted server by ID (the API has no per-server endpoint)
+func getFederatedServer(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	qual := d.EqualsQuals["id"]
+	if qual == nil {
+		return nil, fmt.Errorf("id qualifier not provided")
+	}
+	id := qual.GetInt64Value()
+
+	servers, err := fetchTrustedServers(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	for _, server := range servers {
+		if server.ID == id {
+			return server, nil
+		}
+	}
+	return nil, fmt.Errorf("trusted server with ID %d not found", id)
+}
+
+// trustedServerStatusName maps the numeric trust status to a readable name
+func trustedServerStatusName(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	status, ok := d.Value.(int)
+	if !ok {
+		return nil, nil
+	}
+	switch status {
+	case 1:
+		return "ok", nil
+	case 2:
+		return "pending", nil
+	case 3:
+		return "failure", nil
+	case 4:
+		return "access_revoked", nil
+	}
+	return fmt.Sprintf("%d", status), nil
+}
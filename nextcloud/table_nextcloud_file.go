@@ -0,0 +1,324 @@
+package nextcloud
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// filePropfindBody lists the properties requested for every file
+const filePropfindBody = `<?xml version="1.0" encoding="utf-8"?>
+<d:propfind xmlns:d="DAV:" xmlns:oc="http://owncloud.org/ns" xmlns:nc="http://nextcloud.org/ns">
+  <d:prop>
+    <d:getlastmodified/>
+    <d:getetag/>
+    <d:getcontenttype/>
+    <d:getcontentlength/>
+    <d:resourcetype/>
+    <oc:fileid/>
+    <oc:size/>
+    <oc:permissions/>
+    <oc:favorite/>
+    <oc:owner-id/>
+    <oc:owner-display-name/>
+    <oc:checksums/>
+    <oc:share-types/>
+  </d:prop>
+</d:propfind>`
+
+// davFileProp holds the WebDAV properties of a file or folder
+type davFileProp struct {
+	LastModified  string `xml:"DAV: getlastmodified"`
+	ETag          string `xml:"DAV: getetag"`
+	ContentType   string `xml:"DAV: getcontenttype"`
+	ContentLength string `xml:"DAV: getcontentlength"`
+	ResourceType  struct {
+		Collection *struct{} `xml:"DAV: collection"`
+	} `xml:"DAV: resourcetype"`
+	FileID           string   `xml:"http://owncloud.org/ns fileid"`
+	Size             string   `xml:"http://owncloud.org/ns size"`
+	Permissions      string   `xml:"http://owncloud.org/ns permissions"`
+	Favorite         string   `xml:"http://owncloud.org/ns favorite"`
+	OwnerID          string   `xml:"http://owncloud.org/ns owner-id"`
+	OwnerDisplayName string   `xml:"http://owncloud.org/ns owner-display-name"`
+	Checksums        []string `xml:"http://owncloud.org/ns checksums>checksum"`
+	ShareTypes       []int    `xml:"http://owncloud.org/ns share-types>share-type"`
+}
+
+// ncFile is a row of the nextcloud_file table
+type ncFile struct {
+	Path             string
+	ParentPath       string
+	Name             string
+	IsDir            bool
+	Size             int64
+	ContentType      string
+	ETag             string
+	LastModified     time.Time
+	FileID           int64
+	Permissions      string
+	Favorite         bool
+	OwnerID          string
+	OwnerDisplayName string
+	ChecksumSHA1     string
+	ChecksumMD5      string
+	ChecksumAdler32  string
+	ShareTypes       []int
+	Depth            int
+}
+
+// tableNextcloudFile defines the schema for the files of the configured user
+func tableNextcloudFile() *plugin.Table {
+	return &plugin.Table{
+		Name:        "nextcloud_file",
+		Description: "Files and folders of the configured user, or of as_user for admins (WebDAV), one directory at a time or, with max_depth, a walk of its subdirectories",
+		List: &plugin.ListConfig{
+			Hydrate: listFiles,
+			KeyColumns: plugin.KeyColumnSlice{
+				{Name: "parent_path", Require: plugin.Optional},
+				{Name: "max_depth", Require: plugin.Optional},
+				{Name: "as_user", Require: plugin.Optional},
+			},
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "path", Type: proto.ColumnType_STRING, Description: "Path of the item relative to the user's root", Transform: transform.FromField("Path")},
+			{Name: "parent_path", Type: proto.ColumnType_STRING, Description: "Directory being listed (defaults to the root, /)", Transform: transform.FromField("ParentPath")},
+			{Name: "max_depth", Type: proto.ColumnType_INT, Description: "How many levels below parent_path to list (defaults to 1, the directory itself); each level costs one request per folder", Transform: transform.FromQual("max_depth")},
+			{Name: "as_user", Type: proto.ColumnType_STRING, Description: "User whose files are read (defaults to the configured user); requires admin credentials", Transform: transform.FromQual("as_user")},
+			{Name: "depth", Type: proto.ColumnType_INT, Description: "Level of the item below parent_path (1 for its direct children)", Transform: transform.FromField("Depth")},
+			{Name: "name", Type: proto.ColumnType_STRING, Description: "Name of the item", Transform: transform.FromField("Name")},
+			{Name: "is_dir", Type: proto.ColumnType_BOOL, Description: "True if the item is a folder", Transform: transform.FromField("IsDir")},
+			{Name: "size", Type: proto.ColumnType_INT, Description: "Size in bytes (recursive for folders)", Transform: transform.FromField("Size")},
+			{Name: "content_type", Type: proto.ColumnType_STRING, Description: "Mimetype of the file", Transform: transform.FromField("ContentType").NullIfZero()},
+			{Name: "etag", Type: proto.ColumnType_STRING, Description: "ETag of the item", Transform: transform.FromField("ETag")},
+			{Name: "last_modified", Type: proto.ColumnType_TIMESTAMP, Description: "Last modification time", Transform: transform.FromField("LastModified").NullIfZero()},
+			{Name: "file_id", Type: proto.ColumnType_INT, Description: "Nextcloud file ID", Transform: transform.FromField("FileID")},
+			{Name: "permissions", Type: proto.ColumnType_STRING, Description: "WebDAV permission letters (e.g. RGDNVW)", Transform: transform.FromField("Permissions")},
+			{Name: "favorite", Type: proto.ColumnType_BOOL, Description: "True if the item is marked as favorite", Transform: transform.FromField("Favorite")},
+			{Name: "owner_id", Type: proto.ColumnType_STRING, Description: "User ID of the owner", Transform: transform.FromField("OwnerID")},
+			{Name: "owner_display_name", Type: proto.ColumnType_STRING, Description: "Display name of the owner", Transform: transform.FromField("OwnerDisplayName")},
+			{Name: "is_shared", Type: proto.ColumnType_BOOL, Description: "True if the item is shared by the configured user", Transform: transform.FromField("ShareTypes").Transform(hasShareTypes)},
+			{Name: "share_types", Type: proto.ColumnType_JSON, Description: "Share types of the item (0 user, 1 group, 3 public link, 4 email, 6 federated, 7 circle, 10 Talk room), empty when not shared", Transform: transform.FromField("ShareTypes")},
+			{Name: "checksum_sha1", Type: proto.ColumnType_STRING, Description: "SHA1 checksum of the file, NULL when the server did not compute it", Transform: transform.FromField("ChecksumSHA1").NullIfZero()},
+			{Name: "checksum_md5", Type: proto.ColumnType_STRING, Description: "MD5 checksum of the file, NULL when the server did not compute it", Transform: transform.FromField("ChecksumMD5").NullIfZero()},
+			{Name: "checksum_adler32", Type: proto.ColumnType_STRING, Description: "Adler-32 checksum of the file, NULL when the server did not compute it", Transform: transform.FromField("ChecksumAdler32").NullIfZero()},
+		}),
+	}
+}
+
+// listFiles streams the items of the requested directory, down to max_depth
+// levels, as they are decoded
+func listFiles(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	parent := "/"
+	if qual := d.EqualsQuals["parent_path"]; qual != nil {
+		parent = cleanDAVPath(qual.GetStringValue())
+	}
+	maxDepth := 1
+	if qual := d.EqualsQuals["max_depth"]; qual != nil {
+		maxDepth = int(qual.GetInt64Value())
+		if maxDepth < 1 {
+			return nil, fmt.Errorf("invalid max_depth %d: must be 1 or more", maxDepth)
+		}
+	}
+
+	user, err := davTargetUser(ctx, d, client)
+	if err != nil {
+		return nil, err
+	}
+
+	err = walkFiles(ctx, client, user, parent, maxDepth, maxConcurrency(d.Connection), func(file ncFile) bool {
+		file.ParentPath = parent
+		d.StreamListItem(ctx, file)
+		return d.RowsRemaining(ctx) != 0
+	})
+	if err != nil {
+		return nil, asUserError(client, user, err)
+	}
+	return nil, nil
+}
+
+// walkFiles calls fn for every item below root in the files of user, down to
+// maxDepth levels. Each folder is listed with its own Depth: 1 PROPFIND, since
+// many servers refuse Depth: infinity, by a pool of concurrency workers so that
+// sibling folders are listed in parallel. fn is called from the calling goroutine only; the
+// walk stops as soon as it returns false or ctx is cancelled.
+func walkFiles(ctx context.Context, client *NextcloudClient, user, root string, maxDepth, concurrency int, fn func(ncFile) bool) error {
+	type folder struct {
+		path  string
+		depth int
+	}
+	// a result carries either an item or, with done set, the end of a folder listing
+	type result struct {
+		file ncFile
+		done bool
+		err  error
+	}
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	jobs := make(chan folder)
+	defer close(jobs)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan result)
+	send := func(r result) bool {
+		select {
+		case results <- r:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for dir := range jobs {
+				endpoint := davFilesEndpoint(user, dir.path)
+				err := davStream(ctx, client, "PROPFIND", endpoint, "1", filePropfindBody, func(r davResponse[davFileProp]) bool {
+					file, ok := fileFromDAV(user, r)
+					// the directory itself is part of the response
+					if !ok || file.Path == dir.path {
+						return true
+					}
+					file.Depth = dir.depth + 1
+					return send(result{file: file})
+				})
+				if !send(result{done: true, err: err}) {
+					return
+				}
+			}
+		}()
+	}
+
+	pending := []folder{{path: root}}
+	listing := 0
+	for len(pending) > 0 || listing > 0 {
+		// offer the next folder to the workers only when there is one
+		var next chan folder
+		var head folder
+		if len(pending) > 0 {
+			next, head = jobs, pending[0]
+		}
+		select {
+		case next <- head:
+			pending = pending[1:]
+			listing++
+		case r := <-results:
+			if r.done {
+				listing--
+				if r.err != nil {
+					return r.err
+				}
+				continue
+			}
+			if r.file.IsDir && r.file.Depth < maxDepth {
+				pending = append(pending, folder{path: r.file.Path, depth: r.file.Depth})
+			}
+			if !fn(r.file) {
+				return nil
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// davFilesEndpoint returns the files endpoint of user for the given path
+func davFilesEndpoint(user, filePath string) string {
+	segments := strings.Split(strings.Trim(filePath, "/"), "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	endpoint := fmt.Sprintf("remote.php/dav/files/%s/%s", url.PathEscape(user), strings.Join(segments, "/"))
+	return strings.TrimSuffix(endpoint, "/") + "/"
+}
+
+// cleanDAVPath normalizes a user supplied path to the "/a/b" form
+func cleanDAVPath(p string) string {
+	return path.Clean("/" + strings.Trim(p, "/"))
+}
+
+// fileFromDAV converts a PROPFIND response into an ncFile
+func fileFromDAV(user string, r davResponse[davFileProp]) (ncFile, bool) {
+	prop, ok := r.OKProp()
+	if !ok {
+		return ncFile{}, false
+	}
+
+	href, err := url.PathUnescape(r.Href)
+	if err != nil {
+		href = r.Href
+	}
+	// strip everything up to the user's files root, whatever the install subpath
+	root := "/remote.php/dav/files/" + user
+	if i := strings.Index(href, root); i >= 0 {
+		href = href[i+len(root):]
+	}
+	filePath := cleanDAVPath(href)
+
+	file := ncFile{
+		Path:             filePath,
+		Name:             path.Base(filePath),
+		IsDir:            prop.ResourceType.Collection != nil,
+		ContentType:      prop.ContentType,
+		ETag:             strings.Trim(prop.ETag, `"`),
+		LastModified:     parseDAVTime(prop.LastModified),
+		Permissions:      prop.Permissions,
+		Favorite:         prop.Favorite == "1",
+		OwnerID:          prop.OwnerID,
+		OwnerDisplayName: prop.OwnerDisplayName,
+		ShareTypes:       append([]int{}, prop.ShareTypes...),
+	}
+	file.FileID, _ = strconv.ParseInt(prop.FileID, 10, 64)
+	if prop.Size != "" {
+		file.Size, _ = strconv.ParseInt(prop.Size, 10, 64)
+	} else {
+		file.Size, _ = strconv.ParseInt(prop.ContentLength, 10, 64)
+	}
+	file.setChecksums(prop.Checksums)
+	return file, true
+}
+
+// hasShareTypes reports whether the share types of a file are not empty
+func hasShareTypes(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	shareTypes, _ := d.Value.([]int)
+	return len(shareTypes) > 0, nil
+}
+
+// setChecksums fills the checksums of the file from the oc:checksums values,
+// each a space-delimited list of "ALGORITHM:hex" entries (e.g. "SHA1:ab12 MD5:cd34")
+func (f *ncFile) setChecksums(values []string) {
+	for _, value := range values {
+		for _, entry := range strings.Fields(value) {
+			algorithm, sum, ok := strings.Cut(entry, ":")
+			if !ok || sum == "" {
+				continue
+			}
+			switch strings.ToUpper(algorithm) {
+			case "SHA1":
+				f.ChecksumSHA1 = strings.ToLower(sum)
+			case "MD5":
+				f.ChecksumMD5 = strings.ToLower(sum)
+			case "ADLER32":
+				f.ChecksumAdler32 = strings.ToLower(sum)
+			}
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package nextcloud
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// fileSearchBody is the DAV SEARCH (basicsearch) request template: scope, name pattern, optional limit
+const fileSearchBody = `<?xml version="1.0" encoding="UTF-8"?>
+<d:searchrequest xmlns:d="DAV:" xmlns:oc="http://owncloud.org/ns" xmlns:nc="http://nextcloud.org/ns">
+  <d:basicsearch>
+    <d:select>
+      <d:prop>
+        <d:getlastmodified/>
+        <d:getetag/>
+        <d:getcontenttype/>
+        <d:getcontentlength/>
+        <d:resourcetype/>
+        <oc:fileid/>
+        <oc:size/>
+        <oc:permissions/>
+        <oc:favorite/>
+        <oc:owner-id/>
+        <oc:owner-display-name/>
+      </d:prop>
+    </d:select>
+    <d:from>
+      <d:scope>
+        <d:href>%s</d:href>
+        <d:depth>infinity</d:depth>
+      </d:scope>
+    </d:from>
+    <d:where>
+      <d:like>
+        <d:prop>
+          <d:displayname/>
+        </d:prop>
+        <d:literal>%s</d:literal>
+      </d:like>
+    </d:where>
+    <d:orderby/>%s
+  </d:basicsearch>
+</d:searchrequest>`
+
+// tableNextcloudFileSearch defines the schema for a name search across the files of the configured user
+func tableNextcloudFileSearch() *plugin.Table {
+	return &plugin.Table{
+		Name:        "nextcloud_file_search",
+		Description: "Files and folders of the configured user (or of as_user for admins) matching a name pattern, found with a WebDAV SEARCH (requires a query qualifier)",
+		List: &plugin.ListConfig{
+			Hydrate: listFileSearch,
+			KeyColumns: plugin.KeyColumnSlice{
+				{Name: "query", Require: plugin.Required},
+				{Name: "path_prefix", Require: plugin.Optional},
+				{Name: "as_user", Require: plugin.Optional},
+			},
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "query", Type: proto.ColumnType_STRING, Description: "Name pattern to search for; % matches any characters (e.g. %.pdf)", Transform: transform.FromQual("query")},
+			{Name: "path_prefix", Type: proto.ColumnType_STRING, Description: "Folder to search in (defaults to the root, /)", Transform: transform.FromQual("path_prefix")},
+			{Name: "as_user", Type: proto.ColumnType_STRING, Description: "User whose files are read (defaults to the configured user); requires admin credentials", Transform: transform.FromQual("as_user")},
+			{Name: "path", Type: proto.ColumnType_STRING, Description: "Path of the item relative to the user's root", Transform: transform.FromField("Path")},
+			{Name: "parent_path", Type: proto.ColumnType_STRING, Description: "Folder containing the item", Transform: transform.FromField("ParentPath")},
+			{Name: "name", Type: proto.ColumnType_STRING, Description: "Name of the item", Transform: transform.FromField("Name")},
+			{Name: "is_dir", Type: proto.ColumnType_BOOL, Description: "True if the item is a folder", Transform: transform.FromField("IsDir")},
+			{Name: "size", Type: proto.ColumnType_INT, Description: "Size in bytes (recursive for folders)", Transform: transform.FromField("Size")},
+			{Name: "content_type", Type: proto.ColumnType_STRING, Description: "Mimetype of the file", Transform: transform.FromField("ContentType").NullIfZero()},
+			{Name: "etag", Type: proto.ColumnType_STRING, Description: "ETag of the item", Transform: transform.FromField("ETag")},
+			{Name: "last_modified", Type: proto.ColumnType_TIMESTAMP, Description: "Last modification time", Transform: transform.FromField("LastModified").NullIfZero()},
+			{Name: "file_id", Type: proto.ColumnType_INT, Description: "Nextcloud file ID", Transform: transform.FromField("FileID")},
+			{Name: "permissions", Type: proto.ColumnType_STRING, Description: "WebDAV permission letters (e.g. RGDNVW)", Transform: transform.FromField("Permissions")},
+			{Name: "favorite", Type: proto.ColumnType_BOOL, Description: "True if the item is marked as favorite", Transform: transform.FromField("Favorite")},
+			{Name: "owner_id", Type: proto.ColumnType_STRING, Description: "User ID of the owner", Transform: transform.FromField("OwnerID")},
+		}),
+	}
+}
+
+// listFileSearch sends a SEARCH request scoped to path_prefix and streams the matching items
+func listFileSearch(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	query := d.EqualsQuals["query"].GetStringValue()
+	if query == "" {
+		return nil, fmt.Errorf("query qualifier not provided")
+	}
+
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := davTargetUser(ctx, d, client)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := "/"
+	if qual := d.EqualsQuals["path_prefix"]; qual != nil {
+		prefix = cleanDAVPath(qual.GetStringValue())
+	}
+
+	// Let the server stop early when the query has a limit
+	limit := ""
+	if d.QueryContext.Limit != nil {
+		limit = fmt.Sprintf("\n    <d:limit><d:nresults>%d</d:nresults></d:limit>", *d.QueryContext.Limit)
+	}
+
+	scope := "/" + strings.TrimSuffix(davFilesEndpoint(user, prefix), "/")
+	scope = strings.TrimPrefix(scope, "/remote.php/dav")
+	body := fmt.Sprintf(fileSearchBody, xmlEscape(scope), xmlEscape(query), limit)
+
+	err = davStream(ctx, client, "SEARCH", "remote.php/dav/", "", body, func(r davResponse[davFileProp]) bool {
+		file, ok := fileFromDAV(user, r)
+		if !ok {
+			return true
+		}
+		file.ParentPath = path.Dir(file.Path)
+		d.StreamListItem(ctx, file)
+		return d.RowsRemaining(ctx) != 0
+	})
+	if err != nil {
+		return nil, asUserError(client, user, err)
+	}
+	return nil, nil
+}
+
+// xmlEscape escapes a value for use as XML character data
+func xmlEscape(value string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(value))
+	return b.String()
+}
@@ -0,0 +1,163 @@
+package nextcloud
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// davTree is a mock WebDAV files tree: the children of each folder, folders
+// ending with "/"
+type davTree map[string][]string
+
+// handler answers the Depth: 1 PROPFIND of the folders of the tree for user,
+// counting the listed folders
+func (tree davTree) handler(t testing.TB, user string, listed *sync.Map) http.Handler {
+	root := "/remote.php/dav/files/" + user
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PROPFIND" || !strings.HasPrefix(r.URL.Path, root+"/") {
+			http.NotFound(w, r)
+			return
+		}
+		dir := cleanDAVPath(strings.TrimPrefix(r.URL.Path, root))
+		children, ok := tree[dir]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if listed != nil {
+			listed.Store(dir, true)
+		}
+
+		var b strings.Builder
+		b.WriteString(`<?xml version="1.0"?><d:multistatus xmlns:d="DAV:" xmlns:oc="http://owncloud.org/ns">`)
+		writeDAVEntry(&b, root, dir, true)
+		for _, child := range children {
+			isDir := strings.HasSuffix(child, "/")
+			writeDAVEntry(&b, root, path.Join(dir, child), isDir)
+		}
+		b.WriteString(`</d:multistatus>`)
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		w.WriteHeader(http.StatusMultiStatus)
+		if _, err := w.Write([]byte(b.String())); err != nil {
+			t.Errorf("writing PROPFIND response: %v", err)
+		}
+	})
+}
+
+// writeDAVEntry writes the <d:response> of a file or folder
+func writeDAVEntry(b *strings.Builder, root, filePath string, isDir bool) {
+	href, resourceType := root+filePath, ""
+	if isDir {
+		href, resourceType = strings.TrimSuffix(href, "/")+"/", "<d:collection/>"
+	}
+	fmt.Fprintf(b, `<d:response><d:href>%s</d:href><d:propstat><d:prop><d:resourcetype>%s</d:resourcetype><oc:size>1</oc:size><oc:fileid>1</oc:fileid></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response>`, href, resourceType)
+}
+
+// nestedTree has folders nested three levels below the root
+var nestedTree = davTree{
+	"/":      {"a/", "f.txt"},
+	"/a":     {"b/", "g.txt"},
+	"/a/b":   {"h.txt", "c/"},
+	"/a/b/c": {"i.txt"},
+}
+
+func TestWalkFilesMaxDepth(t *testing.T) {
+	tests := []struct {
+		maxDepth int
+		want     []string
+		listed   []string
+	}{
+		{maxDepth: 1, want: []string{"/a:1", "/f.txt:1"}, listed: []string{"/"}},
+		{maxDepth: 2, want: []string{"/a/b:2", "/a/g.txt:2", "/a:1", "/f.txt:1"}, listed: []string{"/", "/a"}},
+		{maxDepth: 3, want: []string{"/a/b/c:3", "/a/b/h.txt:3", "/a/b:2", "/a/g.txt:2", "/a:1", "/f.txt:1"}, listed: []string{"/", "/a", "/a/b"}},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("max_depth=%d", tt.maxDepth), func(t *testing.T) {
+			var listed sync.Map
+			client := newTestClient(t, nestedTree.handler(t, "admin", &listed))
+
+			var got []string
+			err := walkFiles(context.Background(), client, "admin", "/", tt.maxDepth, 4, func(file ncFile) bool {
+				got = append(got, fmt.Sprintf("%s:%d", file.Path, file.Depth))
+				return true
+			})
+			if err != nil {
+				t.Fatalf("walkFiles: %v", err)
+			}
+			sort.Strings(got)
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+
+			var folders []string
+			listed.Range(func(key, _ any) bool {
+				folders = append(folders, key.(string))
+				return true
+			})
+			sort.Strings(folders)
+			if strings.Join(folders, " ") != strings.Join(tt.listed, " ") {
+				t.Errorf("listed folders %v, want %v", folders, tt.listed)
+			}
+		})
+	}
+}
+
+func TestWalkFilesStopsEarly(t *testing.T) {
+	client := newTestClient(t, nestedTree.handler(t, "admin", nil))
+
+	count := 0
+	err := walkFiles(context.Background(), client, "admin", "/", 3, 4, func(file ncFile) bool {
+		count++
+		return false
+	})
+	if err != nil {
+		t.Fatalf("walkFiles: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("fn called %d times after returning false, want 1", count)
+	}
+}
+
+// BenchmarkWalkFiles compares listing a tree of 40 folders one folder at a
+// time and with the default pool of workers, against a server taking 2ms per
+// PROPFIND
+func BenchmarkWalkFiles(b *testing.B) {
+	tree := davTree{}
+	for i := 0; i < 40; i++ {
+		dir := fmt.Sprintf("dir%d", i)
+		tree["/"] = append(tree["/"], dir+"/")
+		for j := 0; j < 10; j++ {
+			tree["/"+dir] = append(tree["/"+dir], fmt.Sprintf("file%d.txt", j))
+		}
+	}
+	handler := tree.handler(b, "admin", nil)
+	client := newTestClient(b, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(2 * time.Millisecond)
+		handler.ServeHTTP(w, r)
+	}))
+
+	for _, concurrency := range []int{1, defaultMaxConcurrency} {
+		b.Run(fmt.Sprintf("concurrency=%d", concurrency), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				count := 0
+				err := walkFiles(context.Background(), client, "admin", "/", 2, concurrency, func(ncFile) bool {
+					count++
+					return true
+				})
+				if err != nil {
+					b.Fatal(err)
+				}
+				if count != 440 {
+					b.Fatalf("got %d items, want 440", count)
+				}
+			}
+		})
+	}
+}
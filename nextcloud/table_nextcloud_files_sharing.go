@@ -2,9 +2,14 @@
 package nextcloud
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
@@ -27,18 +32,95 @@ type ocsShare struct {
 	Owner                 string  `json:"displayname_owner"`
 	TimeCreated           int     `json:"stime"`
 	TimeModified          int     `json:"item_mtime"`
+	Mimetype              string  `json:"mimetype"`
+	ItemType              string  `json:"item_type"`
+	ItemSource            int64   `json:"item_source"`
+	FileSource            int64   `json:"file_source"`
+	Storage               int64   `json:"storage"`
+	StorageID             string  `json:"storage_id"`
+	Note                  string  `json:"note"`
+	Label                 string  `json:"label"`
+	HideDownload          ocsBool `json:"hide_download"`
+	SendPasswordByTalk    bool    `json:"send_password_by_talk"`
+	Parent                *int64  `json:"parent"`
+	UIDFileOwner          string  `json:"uid_file_owner"`
+
+	// Tags of the shared item, only returned with include_tags
+	Tags []string `json:"tags"`
+
+	// Recipient is the effective recipient of the row, set by listShares
+	Recipient string `json:"-"`
+}
+
+// ocsBool decodes boolean flags that the OCS API returns either as JSON
+// booleans or as 0/1 integers (e.g. hide_download).
+type ocsBool bool
+
+// UnmarshalJSON accepts true/false, 0/1 and their string forms
+func (b *ocsBool) UnmarshalJSON(data []byte) error {
+	switch strings.Trim(string(bytes.TrimSpace(data)), `"`) {
+	case "true", "1":
+		*b = true
+	case "false", "0", "", "null":
+		*b = false
+	default:
+		return fmt.Errorf("invalid boolean value %s", data)
+	}
+	return nil
+}
+
+// ocsBoolValue converts an ocsBool into a plain bool for BOOL columns
+func ocsBoolValue(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	if b, ok := d.Value.(ocsBool); ok {
+		return bool(b), nil
+	}
+	return d.Value, nil
 }
 
-// ocsShareListResponse wraps the JSON envelope for the Shares API list
-type ocsShareListResponse struct {
-	Ocs struct {
-		Meta struct {
-			Status     string `json:"status"`
-			StatusCode int    `json:"statuscode"`
-			Message    string `json:"message"`
-		} `json:"meta"`
-		Data []ocsShare `json:"data"`
-	} `json:"ocs"`
+// sharePermissions is the decoded form of the share permission bitmask
+type sharePermissions struct {
+	CanRead   bool
+	CanUpdate bool
+	CanCreate bool
+	CanDelete bool
+	CanShare  bool
+}
+
+// Share permission bits as defined by the Files Sharing API
+const (
+	sharePermissionRead   = 1
+	sharePermissionUpdate = 2
+	sharePermissionCreate = 4
+	sharePermissionDelete = 8
+	sharePermissionShare  = 16
+)
+
+// ocsShareData holds the "data" member of a shares response. Depending on the
+// Nextcloud version, a share detail is returned either as a one-element array
+// or as a single object, so both shapes are accepted.
+type ocsShareData []ocsShare
+
+// UnmarshalJSON decodes either an array of shares or a single share object
+func (s *ocsShareData) UnmarshalJSON(b []byte) error {
+	trimmed := bytes.TrimSpace(b)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		*s = nil
+		return nil
+	}
+	if trimmed[0] == '{' {
+		var share ocsShare
+		if err := json.Unmarshal(trimmed, &share); err != nil {
+			return err
+		}
+		*s = ocsShareData{share}
+		return nil
+	}
+	var shares []ocsShare
+	if err := json.Unmarshal(trimmed, &shares); err != nil {
+		return err
+	}
+	*s = shares
+	return nil
 }
 
 // tableNextcloudShare defines the schema and list/get configuration for share objects
@@ -48,15 +130,24 @@ func tableNextcloudShare() *plugin.Table {
 		Description: "Nextcloud file shares (including public links)",
 		List: &plugin.ListConfig{
 			Hydrate: listShares,
+			KeyColumns: plugin.KeyColumnSlice{
+				{Name: "path", Require: plugin.Optional},
+				{Name: "parent_path", Require: plugin.Optional},
+				{Name: "subfiles", Require: plugin.Optional},
+				{Name: "shared_with_me", Require: plugin.Optional},
+				{Name: "reshares", Require: plugin.Optional},
+				{Name: "include_tags", Require: plugin.Optional},
+				{Name: "expand_recipients", Require: plugin.Optional},
+			},
 		},
 		Get: &plugin.GetConfig{
 			KeyColumns: plugin.SingleColumn("id"),
 			Hydrate:    getShare,
 		},
-		Columns: []*plugin.Column{
+		Columns: commonColumns([]*plugin.Column{
 			{Name: "id", Type: proto.ColumnType_INT, Description: "Share ID", Transform: transform.FromField("ID")},
 			{Name: "path", Type: proto.ColumnType_STRING, Description: "Path of the shared object", Transform: transform.FromField("Path")},
-			{Name: "name_owner", Type: proto.ColumnType_STRING, Description: "Name of the owner", Transform: transform.FromField("Owner")},
+			{Name: "name_owner", Type: proto.ColumnType_STRING, Description: "Name of the owner (the remote owner when shared_with_me is true)", Transform: transform.FromField("Owner")},
 			{Name: "password", Type: proto.ColumnType_STRING, Description: "Password protecting the share, if any", Transform: transform.FromField("Password")},
 			{Name: "time_created", Type: proto.ColumnType_TIMESTAMP, Description: "Creation time of the share", Transform: transform.FromField("TimeCreated").Transform(transform.UnixToTimestamp)},
 			{Name: "time_modified", Type: proto.ColumnType_TIMESTAMP, Description: "Modified time of the share", Transform: transform.FromField("TimeModified").Transform(transform.UnixToTimestamp)},
@@ -65,41 +156,130 @@ func tableNextcloudShare() *plugin.Table {
 			{Name: "share_with_displayname", Type: proto.ColumnType_STRING, Description: "User or group the resource is shared with", Transform: transform.FromField("ShareWithDisplayName")},
 			{Name: "share_type", Type: proto.ColumnType_INT, Description: "Type of the share (0=user, 3=public link)", Transform: transform.FromField("ShareType")},
 			{Name: "permissions", Type: proto.ColumnType_INT, Description: "Permission mask", Transform: transform.FromField("Permissions")},
+			{Name: "can_read", Type: proto.ColumnType_BOOL, Description: "True if the share grants read permission", Transform: transform.FromField("Permissions").Transform(decodeSharePermissions).TransformP(sharePermissionFlag, "CanRead")},
+			{Name: "can_update", Type: proto.ColumnType_BOOL, Description: "True if the share grants update permission", Transform: transform.FromField("Permissions").Transform(decodeSharePermissions).TransformP(sharePermissionFlag, "CanUpdate")},
+			{Name: "can_create", Type: proto.ColumnType_BOOL, Description: "True if the share grants create permission", Transform: transform.FromField("Permissions").Transform(decodeSharePermissions).TransformP(sharePermissionFlag, "CanCreate")},
+			{Name: "can_delete", Type: proto.ColumnType_BOOL, Description: "True if the share grants delete permission", Transform: transform.FromField("Permissions").Transform(decodeSharePermissions).TransformP(sharePermissionFlag, "CanDelete")},
+			{Name: "can_share", Type: proto.ColumnType_BOOL, Description: "True if the share can be re-shared", Transform: transform.FromField("Permissions").Transform(decodeSharePermissions).TransformP(sharePermissionFlag, "CanShare")},
 			{Name: "public_upload", Type: proto.ColumnType_BOOL, Description: "Whether public upload is allowed", Transform: transform.FromField("PublicUpload")},
 			{Name: "url", Type: proto.ColumnType_STRING, Description: "Public URL of the share", Transform: transform.FromField("URL")},
-			{Name: "owner", Type: proto.ColumnType_STRING, Description: "Owner of the share", Transform: transform.FromField("UIDOwner")},
-			
-		},
+			{Name: "owner", Type: proto.ColumnType_STRING, Description: "Owner of the share (the remote owner when shared_with_me is true)", Transform: transform.FromField("UIDOwner")},
+			{Name: "mimetype", Type: proto.ColumnType_STRING, Description: "Mimetype of the shared item", Transform: transform.FromField("Mimetype").NullIfZero()},
+			{Name: "item_type", Type: proto.ColumnType_STRING, Description: "Type of the shared item (file or folder)", Transform: transform.FromField("ItemType").NullIfZero()},
+			{Name: "item_source", Type: proto.ColumnType_INT, Description: "ID of the shared item", Transform: transform.FromField("ItemSource").NullIfZero()},
+			{Name: "file_source", Type: proto.ColumnType_INT, Description: "File ID of the shared item", Transform: transform.FromField("FileSource").NullIfZero()},
+			{Name: "storage", Type: proto.ColumnType_INT, Description: "Numeric ID of the storage holding the shared item", Transform: transform.FromField("Storage").NullIfZero()},
+			{Name: "storage_id", Type: proto.ColumnType_STRING, Description: "Identifier of the storage holding the shared item", Transform: transform.FromField("StorageID").NullIfZero()},
+			{Name: "note", Type: proto.ColumnType_STRING, Description: "Note attached to the share for the recipient", Transform: transform.FromField("Note").NullIfZero()},
+			{Name: "label", Type: proto.ColumnType_STRING, Description: "Label of the public link share", Transform: transform.FromField("Label").NullIfZero()},
+			{Name: "hide_download", Type: proto.ColumnType_BOOL, Description: "True if downloads are hidden for the public link", Transform: transform.FromField("HideDownload").Transform(ocsBoolValue)},
+			{Name: "send_password_by_talk", Type: proto.ColumnType_BOOL, Description: "True if the share password is sent to the recipient through Talk", Transform: transform.FromField("SendPasswordByTalk")},
+			{Name: "parent_path", Type: proto.ColumnType_STRING, Description: "Folder whose items' shares are listed (subfiles); their path is below it, so filter on parent_path rather than path", Transform: transform.FromQual("parent_path")},
+			{Name: "subfiles", Type: proto.ColumnType_BOOL, Description: "Implied by parent_path, which lists the shares of the items inside that folder", Transform: transform.FromQual("subfiles")},
+			{Name: "shared_with_me", Type: proto.ColumnType_BOOL, Description: "Set to true to list the shares received by the user instead of the ones they created", Transform: transform.FromQual("shared_with_me")},
+			{Name: "reshares", Type: proto.ColumnType_BOOL, Description: "Set to true to also list the re-shares made by other users of the items the user owns. Which re-shares are visible depends on the caller's permissions; admin credentials see the full chain", Transform: transform.FromQual("reshares")},
+			{Name: "parent_id", Type: proto.ColumnType_INT, Description: "ID of the share this one was re-shared from, when the API provides it", Transform: transform.FromField("Parent")},
+			{Name: "include_tags", Type: proto.ColumnType_BOOL, Description: "Set to true to fill the tags column", Transform: transform.FromQual("include_tags")},
+			{Name: "tags", Type: proto.ColumnType_JSON, Description: "Tags of the shared item (e.g. _$!<Favorite>!$_), only when include_tags is true", Transform: transform.FromField("Tags")},
+			{Name: "file_owner", Type: proto.ColumnType_STRING, Description: "Owner of the shared file; differs from owner for re-shares", Transform: transform.FromField("UIDFileOwner").NullIfZero()},
+			{Name: "share_with_circle_name", Type: proto.ColumnType_STRING, Description: "For circle (team) shares, the name of the circle share_with refers to; NULL for other share types or when the Circles app is not enabled", Hydrate: getShareCircleName, Transform: transform.FromValue()},
+			{Name: "expand_recipients", Type: proto.ColumnType_BOOL, Description: "Set to true to get one row per user who gains access through a group or circle share, in the recipient column. Memberships are cached for 5 minutes", Transform: transform.FromQual("expand_recipients")},
+			{Name: "recipient", Type: proto.ColumnType_STRING, Description: "User ID of the recipient: share_with for user shares, each member of the group or circle when expand_recipients is true; NULL otherwise (public links, e-mail, federated shares, or memberships the configured user can't read)", Transform: transform.FromField("Recipient").NullIfZero()},
+			{Name: "compliant", Type: proto.ColumnType_BOOL, Description: "For public links, true if the share complies with the enforced password and expiration policy of the server; NULL for other share types", Hydrate: getShareCompliance, Transform: transform.FromValue()},
+		}),
 	}
 }
 
-// listShares retrieves all shares from the Files Sharing API
+// listShares retrieves the shares created by (or, with shared_with_me, received by) the user
 func listShares(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
-	client, err := GetClient(ctx, d.Connection)
+	client, err := GetClient(ctx, d)
 	if err != nil {
 		return nil, err
 	}
-	endpoint := "ocs/v2.php/apps/files_sharing/api/v1/shares?format=json"
-	resp, err := client.MakeRequest(ctx, "GET", endpoint, nil)
+
+	// Scope the request to a single path when a "path = X" qualifier is present
+	params := url.Values{}
+	params.Set("format", "json")
+	if qual := d.EqualsQuals["path"]; qual != nil {
+		params.Set("path", qual.GetStringValue())
+		params.Set("reshares", "true")
+	}
+	// Shares of the items inside a folder: their path differs from the folder,
+	// which therefore comes from its own qualifier rather than path
+	if qual := d.EqualsQuals["parent_path"]; qual != nil {
+		params.Set("path", qual.GetStringValue())
+		params.Set("subfiles", "true")
+	} else if qual := d.EqualsQuals["subfiles"]; qual != nil && qual.GetBoolValue() {
+		return nil, fmt.Errorf("subfiles requires a parent_path qualifier (the folder whose items' shares are listed)")
+	}
+	// Include the re-shares made by other users (implied by a path qualifier)
+	if qual := d.EqualsQuals["reshares"]; qual != nil && qual.GetBoolValue() {
+		params.Set("reshares", "true")
+	}
+	// Tags of the shared items
+	if qual := d.EqualsQuals["include_tags"]; qual != nil && qual.GetBoolValue() {
+		params.Set("include_tags", "true")
+	}
+	// Shares received by the user rather than created by them
+	if qual := d.EqualsQuals["shared_with_me"]; qual != nil && qual.GetBoolValue() {
+		params.Set("shared_with_me", "true")
+	}
+	endpoint := "ocs/v2.php/apps/files_sharing/api/v1/shares?" + params.Encode()
+	shares, err := ocsGet[ocsShare](ctx, client, endpoint)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	var result ocsShareListResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("error decoding JSON Nextcloud Shares: %w", err)
-	}
-	if result.Ocs.Meta.Status != "ok" {
-		return nil, fmt.Errorf("OCS API error: %s (code %d)", result.Ocs.Meta.Message, result.Ocs.Meta.StatusCode)
+	expand := false
+	if qual := d.EqualsQuals["expand_recipients"]; qual != nil {
+		expand = qual.GetBoolValue()
 	}
 
-	for _, share := range result.Ocs.Data {
-		d.StreamListItem(ctx, share)
+	for _, share := range shares {
+		recipients := []string{""}
+		switch {
+		case share.ShareType == shareTypeUser:
+			recipients = []string{share.ShareWith}
+		case expand && (share.ShareType == shareTypeGroup || share.ShareType == shareTypeCircle):
+			if recipients, err = shareRecipients(ctx, d, client, share); err != nil {
+				return nil, err
+			}
+		}
+		for _, recipient := range recipients {
+			share.Recipient = recipient
+			d.StreamListItem(ctx, share)
+			if d.RowsRemaining(ctx) == 0 {
+				return nil, nil
+			}
+		}
 	}
 	return nil, nil
 }
 
+// shareRecipients resolves the members of the group or circle of a share.
+// Memberships the configured user can't read give a single row with no
+// recipient rather than failing the query.
+func shareRecipients(ctx context.Context, d *plugin.QueryData, client *NextcloudClient, share ocsShare) ([]string, error) {
+	var ids []string
+	var err error
+	if share.ShareType == shareTypeCircle {
+		ids, err = circleMemberIDs(ctx, d, client, share.ShareWith)
+	} else {
+		ids, err = groupMemberIDs(ctx, d, client, share.ShareWith)
+	}
+	if err != nil {
+		if errors.Is(err, ErrNotFound) || errors.Is(err, ErrForbidden) || errors.Is(err, ErrAppNotEnabled) {
+			plugin.Logger(ctx).Warn("shareRecipients", "share_id", share.ID, "share_with", share.ShareWith, "error", err)
+			return []string{""}, nil
+		}
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return []string{""}, nil
+	}
+	return ids, nil
+}
+
 // getShare retrieves a single share by ID
 func getShare(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
 	qual := d.EqualsQuals["id"]
@@ -108,24 +288,131 @@ func getShare(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (
 	}
 	id := qual.GetInt64Value()
 
-	client, err := GetClient(ctx, d.Connection)
+	client, err := GetClient(ctx, d)
 	if err != nil {
 		return nil, err
 	}
 	endpoint := fmt.Sprintf("ocs/v2.php/apps/files_sharing/api/v1/shares/%d?format=json", id)
-	resp, err := client.MakeRequest(ctx, "GET", endpoint, nil)
+	shares, err := ocsGetData[ocsShareData](ctx, client, endpoint)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
+	if len(shares) == 0 {
+		return nil, fmt.Errorf("share with ID %d not found", id)
+	}
+	// API returns a single-element array or a single object, both normalized by ocsShareData
+	share := shares[0]
+	if share.ShareType == shareTypeUser {
+		share.Recipient = share.ShareWith
+	}
+	return share, nil
+}
 
-	var result ocsShareListResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("error decoding JSON Nextcloud Share detail: %w", err)
+// decodeSharePermissions turns the permission bitmask into a sharePermissions struct
+func decodeSharePermissions(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	mask, ok := d.Value.(int)
+	if !ok {
+		return nil, fmt.Errorf("unexpected permissions type %T", d.Value)
 	}
-	if result.Ocs.Meta.Status != "ok" || len(result.Ocs.Data) == 0 {
-		return nil, fmt.Errorf("share with ID %d not found", id)
+	return sharePermissions{
+		CanRead:   mask&sharePermissionRead != 0,
+		CanUpdate: mask&sharePermissionUpdate != 0,
+		CanCreate: mask&sharePermissionCreate != 0,
+		CanDelete: mask&sharePermissionDelete != 0,
+		CanShare:  mask&sharePermissionShare != 0,
+	}, nil
+}
+
+// sharePermissionFlag extracts a single flag (named by the transform param) from decoded permissions
+func sharePermissionFlag(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	perms, ok := d.Value.(sharePermissions)
+	if !ok {
+		return nil, fmt.Errorf("unexpected permissions type %T", d.Value)
+	}
+	switch d.Param.(string) {
+	case "CanRead":
+		return perms.CanRead, nil
+	case "CanUpdate":
+		return perms.CanUpdate, nil
+	case "CanCreate":
+		return perms.CanCreate, nil
+	case "CanDelete":
+		return perms.CanDelete, nil
+	case "CanShare":
+		return perms.CanShare, nil
+	}
+	return nil, fmt.Errorf("unknown share permission %v", d.Param)
+}
+
+// shareExpireDateLayouts are the formats of expire_date across versions
+var shareExpireDateLayouts = []string{"2006-01-02 15:04:05", "2006-01-02"}
+
+// parseShareExpireDate parses the expire_date of a share
+func parseShareExpireDate(value string) (time.Time, bool) {
+	for _, layout := range shareExpireDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// getShareCompliance checks a public link share against the sharing policy
+// of the cached capabilities: a password when passwords are enforced, and an
+// expiration date no later than the maximum number of days after creation
+// when expiration is enforced
+func getShareCompliance(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	share, ok := h.Item.(ocsShare)
+	if !ok || share.ShareType != shareTypePublic {
+		return nil, nil
+	}
+	caps, err := getCapabilities(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	policy := sharePolicyFromCapabilities(caps)
+
+	if policy.PasswordEnforced && (share.Password == nil || *share.Password == "") {
+		return false, nil
+	}
+	if policy.ExpireEnforced {
+		if share.ExpireDate == nil {
+			return false, nil
+		}
+		expires, ok := parseShareExpireDate(*share.ExpireDate)
+		if !ok {
+			return false, nil
+		}
+		if policy.MaxExpireDays > 0 && share.TimeCreated > 0 {
+			// expire_date has a day granularity: allow the rest of the last day
+			limit := time.Unix(int64(share.TimeCreated), 0).AddDate(0, 0, int(policy.MaxExpireDays)+1)
+			if expires.After(limit) {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// getShareCircleName resolves the circle of a circle share to its name. When
+// the Circles app is not enabled, or the circle is not visible to the
+// configured user, the column is left empty instead of failing the query.
+func getShareCircleName(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	share, ok := h.Item.(ocsShare)
+	if !ok || share.ShareType != shareTypeCircle || share.ShareWith == "" {
+		return nil, nil
+	}
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	name, err := circleName(ctx, d, client, share.ShareWith)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) || errors.Is(err, ErrForbidden) {
+			plugin.Logger(ctx).Warn("getShareCircleName", "circle_id", share.ShareWith, "error", err)
+			return nil, nil
+		}
+		return nil, err
 	}
-	// API returns single-element array
-	return result.Ocs.Data[0], nil
+	return name, nil
 }
@@ -0,0 +1,42 @@
+package nextcloud
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestOcsShareDataUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    []string
+	}{
+		{name: "array", payload: `[{"id":"12","share_type":3,"path":"/Photos"},{"id":"13","share_type":0,"path":"/Docs"}]`, want: []string{"12", "13"}},
+		{name: "object", payload: `{"id":"12","share_type":3,"path":"/Photos"}`, want: []string{"12"}},
+		{name: "empty array", payload: `[]`, want: nil},
+		{name: "null", payload: `null`, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var shares ocsShareData
+			if err := json.Unmarshal([]byte(tt.payload), &shares); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			if len(shares) != len(tt.want) {
+				t.Fatalf("got %d shares, want %d", len(shares), len(tt.want))
+			}
+			for i, id := range tt.want {
+				if shares[i].ID != id {
+					t.Errorf("share %d: got id %q, want %q", i, shares[i].ID, id)
+				}
+			}
+		})
+	}
+}
+
+func TestOcsShareDataUnmarshalJSONInvalid(t *testing.T) {
+	var shares ocsShareData
+	if err := json.Unmarshal([]byte(`"12"`), &shares); err == nil {
+		t.Fatal("expected an error for a string payload")
+	}
+}
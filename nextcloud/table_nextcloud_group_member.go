@@ -0,0 +1,145 @@
+package nextcloud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// groupMemberPageSize is the number of members requested per page
+const groupMemberPageSize = 100
+
+// groupMember is a row of the nextcloud_group_member table
+type groupMember struct {
+	GroupID     string
+	UserID      string
+	DisplayName string
+}
+
+// tableNextcloudGroupMember defines the schema for the members of every group
+func tableNextcloudGroupMember() *plugin.Table {
+	return &plugin.Table{
+		Name:        "nextcloud_group_member",
+		Description: "Members of the Nextcloud groups (from the Provisioning API)",
+		List: &plugin.ListConfig{
+			Hydrate: listGroupMembers,
+			KeyColumns: plugin.KeyColumnSlice{
+				{Name: "group_id", Require: plugin.Optional},
+			},
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "group_id", Type: proto.ColumnType_STRING, Description: "Group ID", Transform: transform.FromField("GroupID")},
+			{Name: "user_id", Type: proto.ColumnType_STRING, Description: "User ID of the member", Transform: transform.FromField("UserID")},
+			{Name: "display_name", Type: proto.ColumnType_STRING, Description: "Display name of the member", Transform: transform.FromField("DisplayName")},
+		}),
+	}
+}
+
+// listGroupMembers streams the members of the group of a "group_id = X"
+// qualifier, or of every group, page by page
+func listGroupMembers(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []string
+	if qual := d.EqualsQuals["group_id"]; qual != nil {
+		groups = []string{qual.GetStringValue()}
+	} else {
+		data, err := ocsGetData[struct {
+			Groups []string `json:"groups"`
+		}](ctx, client, "ocs/v1.php/cloud/groups?format=json")
+		if err != nil {
+			return nil, err
+		}
+		groups = data.Groups
+	}
+
+	for _, group := range groups {
+		for offset := 0; ; offset += groupMemberPageSize {
+			members, err := fetchGroupMembersPage(ctx, client, group, offset)
+			if err != nil {
+				return nil, err
+			}
+			for _, member := range members {
+				d.StreamListItem(ctx, member)
+				if d.RowsRemaining(ctx) == 0 {
+					return nil, nil
+				}
+			}
+			if len(members) < groupMemberPageSize {
+				break
+			}
+		}
+	}
+	return nil, nil
+}
+
+// fetchGroupMembersPage returns a page of the members of group with their display names
+func fetchGroupMembersPage(ctx context.Context, client *NextcloudClient, group string, offset int) ([]groupMember, error) {
+	endpoint := fmt.Sprintf("ocs/v1.php/cloud/groups/%s/users/details?format=json&limit=%d&offset=%d", url.PathEscape(group), groupMemberPageSize, offset)
+	data, err := ocsGetData[struct {
+		Users json.RawMessage `json:"users"`
+	}](ctx, client, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the members of group %s: %w", group, err)
+	}
+
+	// users is an object keyed by user ID, or an empty array when there are none
+	users, err := ocsData[map[string]struct {
+		ID          string `json:"id"`
+		DisplayName string `json:"displayname"`
+	}](data.Users, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the members of group %s: %w", group, err)
+	}
+
+	members := make([]groupMember, 0, len(users))
+	for key, user := range users {
+		id := user.ID
+		if id == "" {
+			id = key
+		}
+		members = append(members, groupMember{GroupID: group, UserID: id, DisplayName: user.DisplayName})
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].UserID < members[j].UserID })
+	return members, nil
+}
+
+// membersCacheTTL is how long group and circle memberships are reused from the connection cache
+const membersCacheTTL = 5 * time.Minute
+
+// groupMemberIDs returns the user IDs of the members of group, kept in the
+// connection cache so that the shares of a same group cost a single lookup
+func groupMemberIDs(ctx context.Context, d *plugin.QueryData, client *NextcloudClient, group string) ([]string, error) {
+	cacheKey := "nextcloud_group_members:" + group
+	if cached, ok := d.ConnectionCache.Get(ctx, cacheKey); ok {
+		return cached.([]string), nil
+	}
+
+	var ids []string
+	for offset := 0; ; offset += groupMemberPageSize {
+		members, err := fetchGroupMembersPage(ctx, client, group, offset)
+		if err != nil {
+			return nil, err
+		}
+		for _, member := range members {
+			ids = append(ids, member.UserID)
+		}
+		if len(members) < groupMemberPageSize {
+			break
+		}
+	}
+	if err := d.ConnectionCache.SetWithTTL(ctx, cacheKey, ids, membersCacheTTL); err != nil {
+		plugin.Logger(ctx).Warn("groupMemberIDs", "cache_error", err)
+	}
+	return ids, nil
+}
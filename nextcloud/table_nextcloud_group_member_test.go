@@ -0,0 +1,43 @@
+package nextcloud
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestFetchGroupMembersPage(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    []string
+		wantErr error
+	}{
+		{name: "members", data: `{"users":{"bob":{"id":"bob","displayname":"Bob"},"alice":{"id":"alice","displayname":"Alice"}}}`, want: []string{"alice", "bob"}},
+		{name: "no members", data: `{"users":[]}`},
+		{name: "unexpected shape", data: `{"users":"alice"}`, wantErr: ErrDecode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, rawOCSHandler(tt.data))
+			members, err := fetchGroupMembersPage(context.Background(), client, "staff", 0)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got %v, want an error wrapping %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("fetchGroupMembersPage: %v", err)
+			}
+			if len(members) != len(tt.want) {
+				t.Fatalf("got %d members, want %d", len(members), len(tt.want))
+			}
+			for i, id := range tt.want {
+				if members[i].UserID != id || members[i].GroupID != "staff" {
+					t.Errorf("member %d: got %+v, want %s of staff", i, members[i], id)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package nextcloud
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/quals"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// logPageSize is the number of log entries requested per page
+const logPageSize = 100
+
+// logEntry is a line of the Nextcloud log as returned by the logreader app
+type logEntry struct {
+	ReqID      string          `json:"reqId"`
+	Level      int             `json:"level"`
+	Time       string          `json:"time"`
+	RemoteAddr string          `json:"remoteAddr"`
+	User       string          `json:"user"`
+	App        string          `json:"app"`
+	Method     string          `json:"method"`
+	URL        string          `json:"url"`
+	Message    json.RawMessage `json:"message"`
+	UserAgent  string          `json:"userAgent"`
+	Version    string          `json:"version"`
+}
+
+// logPage is the response of the logreader endpoint
+type logPage struct {
+	Data   []logEntry `json:"data"`
+	Remain bool       `json:"remain"`
+}
+
+// tableNextcloudLog defines the schema for the server log read through the logreader app
+func tableNextcloudLog() *plugin.Table {
+	return &plugin.Table{
+		Name:        "nextcloud_log",
+		Description: "Entries of the Nextcloud log, including the admin_audit entries when they are written to it (requires admin credentials and the logreader app)",
+		List: &plugin.ListConfig{
+			Hydrate: listLogEntries,
+			KeyColumns: plugin.KeyColumnSlice{
+				{Name: "time", Require: plugin.Optional, Operators: []string{">", ">=", "<", "<=", "="}},
+			},
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "time", Type: proto.ColumnType_TIMESTAMP, Description: "Time of the entry", Transform: transform.FromField("Time").Transform(logTime)},
+			{Name: "level", Type: proto.ColumnType_STRING, Description: "Level of the entry (debug, info, warning, error, fatal)", Transform: transform.FromField("Level").Transform(logLevelName)},
+			{Name: "app", Type: proto.ColumnType_STRING, Description: "App that wrote the entry (admin_audit for audit entries)", Transform: transform.FromField("App")},
+			{Name: "user", Type: proto.ColumnType_STRING, Description: "User of the request, if any", Transform: transform.FromField("User").Transform(logDash)},
+			{Name: "method", Type: proto.ColumnType_STRING, Description: "HTTP method of the request", Transform: transform.FromField("Method").Transform(logDash)},
+			{Name: "url", Type: proto.ColumnType_STRING, Description: "URL of the request", Transform: transform.FromField("URL").Transform(logDash)},
+			{Name: "message", Type: proto.ColumnType_STRING, Description: "Message of the entry", Transform: transform.FromField("Message").Transform(logMessage)},
+			{Name: "remote_addr", Type: proto.ColumnType_STRING, Description: "IP address of the client", Transform: transform.FromField("RemoteAddr").Transform(logDash)},
+			{Name: "request_id", Type: proto.ColumnType_STRING, Description: "ID of the request", Transform: transform.FromField("ReqID")},
+			{Name: "user_agent", Type: proto.ColumnType_STRING, Description: "User agent of the client", Transform: transform.FromField("UserAgent").Transform(logDash)},
+			{Name: "version", Type: proto.ColumnType_STRING, Description: "Nextcloud version that wrote the entry", Transform: transform.FromField("Version")},
+		}),
+	}
+}
+
+// listLogEntries pages through the log, newest first, and stops once the
+// entries are older than the lower bound of a time qualifier
+func listLogEntries(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	var after, before time.Time
+	if d.Quals["time"] != nil {
+		for _, q := range d.Quals["time"].Quals {
+			t := q.Value.GetTimestampValue().AsTime()
+			switch q.Operator {
+			case quals.QualOperatorGreater, quals.QualOperatorGreaterOrEqual:
+				after = t
+			case quals.QualOperatorLess, quals.QualOperatorLessOrEqual:
+				before = t
+			case quals.QualOperatorEqual:
+				after, before = t, t
+			}
+		}
+	}
+
+	for offset := 0; ; offset += logPageSize {
+		page, err := fetchLogPage(ctx, client, offset)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range page.Data {
+			t, _ := parseLogTime(entry.Time)
+			// the log is returned newest first: past the lower bound, we are done
+			if !after.IsZero() && !t.IsZero() && t.Before(after) {
+				return nil, nil
+			}
+			if !before.IsZero() && t.After(before) {
+				continue
+			}
+			d.StreamListItem(ctx, entry)
+			if d.RowsRemaining(ctx) == 0 {
+				return nil, nil
+			}
+		}
+		if !page.Remain || len(page.Data) == 0 {
+			return nil, nil
+		}
+	}
+}
+
+// fetchLogPage reads a page of the log from the logreader app, through the
+// api/log route of current versions or the get route of older ones
+func fetchLogPage(ctx context.Context, client *NextcloudClient, offset int) (logPage, error) {
+	var page logPage
+	query := fmt.Sprintf("?offset=%d&count=%d&levels=11111", offset, logPageSize)
+	err := client.GetJSON(ctx, "index.php/apps/logreader/api/log"+query, &page)
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode == 404 {
+		err = client.GetJSON(ctx, "index.php/apps/logreader/get"+query, &page)
+	}
+
+	var disabled *appDisabledError
+	if err = appNotEnabledError("logreader", err); errors.As(err, &disabled) {
+		return page, fmt.Errorf("logreader app not available: enable it to query nextcloud_log (%w)", disabled.Err)
+	}
+	return page, err
+}
+
+// parseLogTime parses the time of a log entry, written by default in ISO 8601 ("c" format)
+func parseLogTime(value string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339, "January 02, 2006 15:04:05", time.RFC1123Z} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// logTime converts the time of a log entry into a timestamp, NULL when its format is unknown
+func logTime(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	value, _ := d.Value.(string)
+	if t, ok := parseLogTime(value); ok {
+		return t, nil
+	}
+	return nil, nil
+}
+
+// logLevelName maps the numeric log level to its name
+func logLevelName(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	level, ok := d.Value.(int)
+	if !ok {
+		return nil, nil
+	}
+	names := []string{"debug", "info", "warning", "error", "fatal"}
+	if level >= 0 && level < len(names) {
+		return names[level], nil
+	}
+	return fmt.Sprintf("%d", level), nil
+}
+
+// logDash returns NULL for the "--" placeholder written for missing request data
+func logDash(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	if value, _ := d.Value.(string); value == "" || value == "--" {
+		return nil, nil
+	}
+	return d.Value, nil
+}
+
+// logMessage returns the message as text; exceptions are logged as JSON objects
+func logMessage(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	raw, ok := d.Value.(json.RawMessage)
+	if !ok || len(raw) == 0 {
+		return nil, nil
+	}
+	return rawConfigValue(raw), nil
+}
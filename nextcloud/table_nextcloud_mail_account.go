@@ -0,0 +1,68 @@
+package nextcloud
+
+import (
+	"context"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// mailAccount is a mail account of the Mail app. Only the fields listed here
+// are decoded, so the stored IMAP/SMTP credentials are never kept.
+type mailAccount struct {
+	ID             int64  `json:"id"`
+	Name           string `json:"name"`
+	EmailAddress   string `json:"emailAddress"`
+	IMAPHost       string `json:"imapHost"`
+	IMAPPort       int    `json:"imapPort"`
+	IMAPUser       string `json:"imapUser"`
+	SMTPHost       string `json:"smtpHost"`
+	SMTPPort       int    `json:"smtpPort"`
+	ProvisioningID *int64 `json:"provisioningId"`
+}
+
+// Provisioned reports whether the account was created by a provisioning configuration
+func (a mailAccount) Provisioned() bool {
+	return a.ProvisioningID != nil
+}
+
+// tableNextcloudMailAccount defines the schema for the mail accounts of the configured user
+func tableNextcloudMailAccount() *plugin.Table {
+	return &plugin.Table{
+		Name:        "nextcloud_mail_account",
+		Description: "Mail accounts of the configured user in the Nextcloud Mail app (accounts are user-scoped, passwords are never returned). The Mail API has no enabled flag: every configured account is active",
+		List: &plugin.ListConfig{
+			Hydrate: listIfAppEnabled("mail", listMailAccounts),
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "id", Type: proto.ColumnType_INT, Description: "Account ID", Transform: transform.FromField("ID")},
+			{Name: "name", Type: proto.ColumnType_STRING, Description: "Name of the account owner shown in sent messages", Transform: transform.FromField("Name")},
+			{Name: "email", Type: proto.ColumnType_STRING, Description: "Email address of the account", Transform: transform.FromField("EmailAddress")},
+			{Name: "imap_host", Type: proto.ColumnType_STRING, Description: "IMAP server", Transform: transform.FromField("IMAPHost")},
+			{Name: "imap_port", Type: proto.ColumnType_INT, Description: "IMAP port", Transform: transform.FromField("IMAPPort")},
+			{Name: "imap_user", Type: proto.ColumnType_STRING, Description: "IMAP login", Transform: transform.FromField("IMAPUser")},
+			{Name: "smtp_host", Type: proto.ColumnType_STRING, Description: "SMTP server", Transform: transform.FromField("SMTPHost")},
+			{Name: "smtp_port", Type: proto.ColumnType_INT, Description: "SMTP port", Transform: transform.FromField("SMTPPort")},
+			{Name: "provisioned", Type: proto.ColumnType_BOOL, Description: "True if the account was provisioned by an administrator", Transform: transform.FromMethod("Provisioned")},
+		}),
+	}
+}
+
+// listMailAccounts streams the mail accounts of the configured user. The Mail
+// API is not OCS and answers with a bare array.
+func listMailAccounts(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	var accounts []mailAccount
+	if err := client.GetJSON(ctx, "index.php/apps/mail/api/accounts", &accounts); err != nil {
+		return nil, appNotEnabledError("mail", err)
+	}
+	for _, account := range accounts {
+		d.StreamListItem(ctx, account)
+	}
+	return nil, nil
+}
@@ -0,0 +1,93 @@
+package nextcloud
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// note represents a note from the Notes app API (plain JSON, no OCS envelope)
+type note struct {
+	ID       int64  `json:"id"`
+	Title    string `json:"title"`
+	Category string `json:"category"`
+	Content  string `json:"content"`
+	Modified int64  `json:"modified"`
+	Favorite bool   `json:"favorite"`
+	ReadOnly bool   `json:"readonly"`
+}
+
+// tableNextcloudNotes defines the schema for the notes of the configured user
+func tableNextcloudNotes() *plugin.Table {
+	return &plugin.Table{
+		Name:        "nextcloud_notes",
+		Description: "Notes of the configured user from the Nextcloud Notes app",
+		List: &plugin.ListConfig{
+			Hydrate: listIfAppEnabled("notes", listNotes),
+			KeyColumns: plugin.KeyColumnSlice{
+				{Name: "category", Require: plugin.Optional},
+			},
+		},
+		Get: &plugin.GetConfig{
+			KeyColumns: plugin.SingleColumn("id"),
+			Hydrate:    getNote,
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "id", Type: proto.ColumnType_INT, Description: "Note ID", Transform: transform.FromField("ID")},
+			{Name: "title", Type: proto.ColumnType_STRING, Description: "Title of the note", Transform: transform.FromField("Title")},
+			{Name: "category", Type: proto.ColumnType_STRING, Description: "Category of the note", Transform: transform.FromField("Category")},
+			{Name: "content", Type: proto.ColumnType_STRING, Description: "Markdown content of the note", Transform: transform.FromField("Content")},
+			{Name: "modified", Type: proto.ColumnType_TIMESTAMP, Description: "Last modification time of the note", Transform: transform.FromField("Modified").Transform(transform.UnixToTimestamp)},
+			{Name: "favorite", Type: proto.ColumnType_BOOL, Description: "True if the note is marked as favorite", Transform: transform.FromField("Favorite")},
+			{Name: "read_only", Type: proto.ColumnType_BOOL, Description: "True if the note is read-only", Transform: transform.FromField("ReadOnly")},
+		}),
+	}
+}
+
+// listNotes streams the notes of the configured user, optionally within a category
+func listNotes(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := "index.php/apps/notes/api/v1/notes"
+	if qual := d.EqualsQuals["category"]; qual != nil {
+		endpoint += "?category=" + url.QueryEscape(qual.GetStringValue())
+	}
+
+	var notes []note
+	if err := client.GetJSON(ctx, endpoint, &notes); err != nil {
+		return nil, appNotEnabledError("notes", err)
+	}
+	for _, n := range notes {
+		d.StreamListItem(ctx, n)
+		if d.RowsRemaining(ctx) == 0 {
+			return nil, nil
+		}
+	}
+	return nil, nil
+}
+
+// getNote retrieves a single note by ID
+func getNote(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	qual := d.EqualsQuals["id"]
+	if qual == nil {
+		return nil, fmt.Errorf("id qualifier not provided")
+	}
+	id := qual.GetInt64Value()
+
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	var n note
+	if err := client.GetJSON(ctx, fmt.Sprintf("index.php/apps/notes/api/v1/notes/%d", id), &n); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
@@ -0,0 +1,122 @@
+package nextcloud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// notification is a notification of the configured user (notifications app)
+type notification struct {
+	ID         int64                `json:"notification_id"`
+	App        string               `json:"app"`
+	User       string               `json:"user"`
+	Time       time.Time            `json:"datetime"`
+	ObjectType string               `json:"object_type"`
+	ObjectID   string               `json:"object_id"`
+	Subject    string               `json:"subject"`
+	Message    string               `json:"message"`
+	Link       string               `json:"link"`
+	Icon       string               `json:"icon"`
+	Actions    []notificationAction `json:"actions"`
+}
+
+// notificationAction is an action offered by a notification (accept, decline...)
+type notificationAction struct {
+	Label   string `json:"label"`
+	Link    string `json:"link"`
+	Type    string `json:"type"`
+	Primary bool   `json:"primary"`
+}
+
+// tableNextcloudNotification defines the schema for the notifications of the configured user
+func tableNextcloudNotification() *plugin.Table {
+	return &plugin.Table{
+		Name:        "nextcloud_notification",
+		Description: "Notifications of the configured user (notifications app). The API has no read state: seen notifications are listed until dismissed",
+		List: &plugin.ListConfig{
+			Hydrate: listIfAppEnabled("notifications", listNotifications),
+			KeyColumns: plugin.KeyColumnSlice{
+				{Name: "app", Require: plugin.Optional},
+				{Name: "object_type", Require: plugin.Optional},
+			},
+		},
+		Get: &plugin.GetConfig{
+			KeyColumns: plugin.SingleColumn("id"),
+			Hydrate:    getNotification,
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "id", Type: proto.ColumnType_INT, Description: "Notification ID", Transform: transform.FromField("ID")},
+			{Name: "app", Type: proto.ColumnType_STRING, Description: "App that sent the notification (e.g. spreed, files_sharing). The API has no filter: filtered client-side", Transform: transform.FromField("App")},
+			{Name: "user", Type: proto.ColumnType_STRING, Description: "User the notification is for", Transform: transform.FromField("User")},
+			{Name: "time", Type: proto.ColumnType_TIMESTAMP, Description: "Time of the notification", Transform: transform.FromField("Time").NullIfZero()},
+			{Name: "object_type", Type: proto.ColumnType_STRING, Description: "Type of the object the notification is about (e.g. chat, remote_share). Filtered client-side", Transform: transform.FromField("ObjectType")},
+			{Name: "object_id", Type: proto.ColumnType_STRING, Description: "ID of the object the notification is about", Transform: transform.FromField("ObjectID")},
+			{Name: "subject", Type: proto.ColumnType_STRING, Description: "Subject of the notification", Transform: transform.FromField("Subject")},
+			{Name: "message", Type: proto.ColumnType_STRING, Description: "Message of the notification", Transform: transform.FromField("Message").NullIfZero()},
+			{Name: "link", Type: proto.ColumnType_STRING, Description: "Link to the object of the notification", Transform: transform.FromField("Link").NullIfZero()},
+			{Name: "icon", Type: proto.ColumnType_STRING, Description: "URL of the icon of the notification", Transform: transform.FromField("Icon").NullIfZero()},
+			{Name: "actions", Type: proto.ColumnType_JSON, Description: "Actions offered by the notification", Transform: transform.FromField("Actions")},
+		}),
+	}
+}
+
+// listNotifications streams the notifications of the configured user. The API
+// returns them all, so app and object_type are filtered while streaming.
+func listNotifications(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	app, objectType := "", ""
+	if qual := d.EqualsQuals["app"]; qual != nil {
+		app = qual.GetStringValue()
+	}
+	if qual := d.EqualsQuals["object_type"]; qual != nil {
+		objectType = qual.GetStringValue()
+	}
+
+	notifications, err := ocsGet[notification](ctx, client, "ocs/v2.php/apps/notifications/api/v2/notifications?format=json")
+	if err != nil {
+		return nil, appNotEnabledError("notifications", err)
+	}
+	for _, n := range notifications {
+		if (app != "" && n.App != app) || (objectType != "" && n.ObjectType != objectType) {
+			continue
+		}
+		d.StreamListItem(ctx, n)
+		if d.RowsRemaining(ctx) == 0 {
+			return nil, nil
+		}
+	}
+	return nil, nil
+}
+
+// getNotification retrieves a single notification by ID
+func getNotification(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	qual := d.EqualsQuals["id"]
+	if qual == nil {
+		return nil, fmt.Errorf("id qualifier not provided")
+	}
+	id := qual.GetInt64Value()
+
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := fmt.Sprintf("ocs/v2.php/apps/notifications/api/v2/notifications/%d?format=json", id)
+	n, err := ocsGetData[notification](ctx, client, endpoint)
+	if errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("notification with ID %d not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
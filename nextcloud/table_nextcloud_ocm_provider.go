@@ -0,0 +1,139 @@
+package nextcloud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// ocmProvider is the Open Cloud Mesh discovery document of the server
+type ocmProvider struct {
+	Enabled       bool              `json:"enabled"`
+	APIVersion    string            `json:"apiVersion"`
+	EndPoint      string            `json:"endPoint"`
+	Provider      string            `json:"provider"`
+	ResourceTypes []ocmResourceType `json:"resourceTypes"`
+	Capabilities  []string          `json:"capabilities"`
+	Source        string            `json:"-"`
+	WebFinger     []webFingerLink   `json:"-"`
+}
+
+// ocmResourceType is a kind of resource the server accepts over OCM (e.g. file)
+type ocmResourceType struct {
+	Name       string            `json:"name"`
+	ShareTypes []string          `json:"shareTypes"`
+	Protocols  map[string]string `json:"protocols"`
+}
+
+// webFingerLink is a link of the WebFinger document of the server
+type webFingerLink struct {
+	Rel  string `json:"rel"`
+	Type string `json:"type,omitempty"`
+	Href string `json:"href"`
+}
+
+// SharingEnabled reports whether remote servers can share resources with this one
+func (p ocmProvider) SharingEnabled() bool {
+	return p.Enabled && len(p.ResourceTypes) > 0
+}
+
+// NotificationsEnabled reports whether the server accepts OCM notifications.
+// Before OCM 1.1 the notifications endpoint is mandatory, so a server that does
+// not list its capabilities supports them.
+func (p ocmProvider) NotificationsEnabled() bool {
+	if !p.Enabled {
+		return false
+	}
+	if p.Capabilities == nil {
+		return true
+	}
+	for _, c := range p.Capabilities {
+		if c == "/notifications" || c == "notifications" {
+			return true
+		}
+	}
+	return false
+}
+
+// ocmDiscoveryEndpoints are tried in order: .well-known/ocm (OCM 1.1, Nextcloud 28
+// or later) then the legacy ocm-provider/ route
+var ocmDiscoveryEndpoints = []string{".well-known/ocm", "ocm-provider/"}
+
+// tableNextcloudOCMProvider defines the schema for the federation (Open Cloud Mesh) discovery of the server (single row)
+func tableNextcloudOCMProvider() *plugin.Table {
+	return &plugin.Table{
+		Name:        "nextcloud_ocm_provider",
+		Description: "Open Cloud Mesh (federation) capabilities published by the server, to check that federated sharing is ready",
+		List: &plugin.ListConfig{
+			Hydrate: listOCMProvider,
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "enabled", Type: proto.ColumnType_BOOL, Description: "True if OCM is enabled on the server", Transform: transform.FromField("Enabled")},
+			{Name: "api_version", Type: proto.ColumnType_STRING, Description: "OCM API version (e.g. 1.0-proposal1, 1.1.0)", Transform: transform.FromField("APIVersion").NullIfZero()},
+			{Name: "endpoint", Type: proto.ColumnType_STRING, Description: "URL of the OCM endpoint remote servers send shares to", Transform: transform.FromField("EndPoint").NullIfZero()},
+			{Name: "provider", Type: proto.ColumnType_STRING, Description: "Name of the OCM provider (e.g. Nextcloud)", Transform: transform.FromField("Provider").NullIfZero()},
+			{Name: "resource_types", Type: proto.ColumnType_JSON, Description: "Resource types accepted over OCM, with their share types and protocols", Transform: transform.FromField("ResourceTypes")},
+			{Name: "capabilities", Type: proto.ColumnType_JSON, Description: "OCM capabilities (OCM 1.1 or later)", Transform: transform.FromField("Capabilities")},
+			{Name: "sharing_enabled", Type: proto.ColumnType_BOOL, Description: "True if remote servers can share resources with this server", Transform: transform.FromMethod("SharingEnabled")},
+			{Name: "notifications_enabled", Type: proto.ColumnType_BOOL, Description: "True if the server accepts OCM notifications (share accepted, declined...)", Transform: transform.FromMethod("NotificationsEnabled")},
+			{Name: "discovery_endpoint", Type: proto.ColumnType_STRING, Description: "Endpoint the discovery document was read from (.well-known/ocm or ocm-provider/), NULL when OCM is disabled", Transform: transform.FromField("Source").NullIfZero()},
+			{Name: "webfinger_links", Type: proto.ColumnType_JSON, Description: "Links of the .well-known/webfinger document of the server, NULL when it has none", Transform: transform.FromField("WebFinger")},
+		}),
+	}
+}
+
+// listOCMProvider reads the OCM discovery document. A server where federation
+// is disabled answers 404 or 501: a single row with enabled = false is returned.
+func listOCMProvider(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	var provider ocmProvider
+	for _, endpoint := range ocmDiscoveryEndpoints {
+		err = client.GetRawJSON(ctx, endpoint, false, &provider)
+		if err == nil {
+			provider.Source = endpoint
+			break
+		}
+		if !ocmNotAvailable(err) {
+			return nil, fmt.Errorf("error fetching Nextcloud OCM discovery from %s: %w", endpoint, err)
+		}
+		plugin.Logger(ctx).Debug("listOCMProvider", "endpoint", endpoint, "error", err)
+	}
+
+	provider.WebFinger = fetchWebFingerLinks(ctx, client)
+
+	d.StreamListItem(ctx, provider)
+	return nil, nil
+}
+
+// ocmNotAvailable reports whether err means the discovery route does not exist or OCM is disabled
+func ocmNotAvailable(err error) bool {
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		return false
+	}
+	return httpErr.StatusCode == 404 || httpErr.StatusCode == 501
+}
+
+// fetchWebFingerLinks returns the links of the WebFinger document describing
+// the server. WebFinger is only served when an app registers a handler, so any
+// error is logged and yields no links.
+func fetchWebFingerLinks(ctx context.Context, client *NextcloudClient) []webFingerLink {
+	var doc struct {
+		Links []webFingerLink `json:"links"`
+	}
+	endpoint := ".well-known/webfinger?resource=" + url.QueryEscape(client.BaseURL)
+	if err := client.GetRawJSON(ctx, endpoint, false, &doc); err != nil {
+		plugin.Logger(ctx).Debug("fetchWebFingerLinks", "error", err)
+		return nil
+	}
+	return doc.Links
+}
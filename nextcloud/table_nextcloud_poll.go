@@ -0,0 +1,138 @@
+package nextcloud
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// poll represents a poll of the Polls app
+type poll struct {
+	ID          int64           `json:"id"`
+	Type        string          `json:"type"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Owner       json.RawMessage `json:"owner"`
+	Created     int64           `json:"created"`
+	Expire      int64           `json:"expire"`
+}
+
+// OwnerID returns the owner user ID, sent as a plain string by older
+// versions of the app and as a user object by newer ones
+func (p poll) OwnerID() string {
+	var id string
+	if json.Unmarshal(p.Owner, &id) == nil {
+		return id
+	}
+	var owner struct {
+		UserID string `json:"userId"`
+		ID     string `json:"id"`
+	}
+	if json.Unmarshal(p.Owner, &owner) == nil {
+		if owner.UserID != "" {
+			return owner.UserID
+		}
+		return owner.ID
+	}
+	return ""
+}
+
+// tableNextcloudPoll defines the schema for the polls of the Polls app
+func tableNextcloudPoll() *plugin.Table {
+	return &plugin.Table{
+		Name:        "nextcloud_poll",
+		Description: "Polls from the Nextcloud Polls app visible to the configured user",
+		List: &plugin.ListConfig{
+			Hydrate: listIfAppEnabled("polls", listPolls),
+		},
+		Get: &plugin.GetConfig{
+			KeyColumns: plugin.SingleColumn("id"),
+			Hydrate:    getPoll,
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "id", Type: proto.ColumnType_INT, Description: "Poll ID", Transform: transform.FromField("ID")},
+			{Name: "title", Type: proto.ColumnType_STRING, Description: "Title of the poll", Transform: transform.FromField("Title")},
+			{Name: "description", Type: proto.ColumnType_STRING, Description: "Description of the poll", Transform: transform.FromField("Description").NullIfZero()},
+			{Name: "type", Type: proto.ColumnType_STRING, Description: "Type of the poll (textPoll or datePoll)", Transform: transform.FromField("Type")},
+			{Name: "owner", Type: proto.ColumnType_STRING, Description: "User ID of the owner", Transform: transform.FromMethod("OwnerID")},
+			{Name: "created", Type: proto.ColumnType_TIMESTAMP, Description: "Creation time of the poll", Transform: transform.FromField("Created").Transform(transform.UnixToTimestamp)},
+			{Name: "expire", Type: proto.ColumnType_TIMESTAMP, Description: "Expiration time of the poll, NULL if it never expires", Transform: transform.FromField("Expire").Transform(transform.UnixToTimestamp)},
+			{Name: "vote_count", Type: proto.ColumnType_INT, Description: "Number of votes cast in the poll", Hydrate: getPollVoteCount, Transform: transform.FromValue()},
+		}),
+	}
+}
+
+// listPolls streams every poll visible to the configured user. The Polls API
+// is not OCS: it answers with a bare {"polls": [...]} object.
+func listPolls(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Polls []poll `json:"polls"`
+	}
+	if err := client.GetJSON(ctx, "index.php/apps/polls/api/v1.0/polls", &result); err != nil {
+		return nil, appNotEnabledError("polls", err)
+	}
+	for _, p := range result.Polls {
+		d.StreamListItem(ctx, p)
+		if d.RowsRemaining(ctx) == 0 {
+			return nil, nil
+		}
+	}
+	return nil, nil
+}
+
+// getPoll retrieves a single poll by ID
+func getPoll(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	qual := d.EqualsQuals["id"]
+	if qual == nil {
+		return nil, fmt.Errorf("id qualifier not provided")
+	}
+	id := qual.GetInt64Value()
+
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Poll poll `json:"poll"`
+	}
+	err = client.GetJSON(ctx, fmt.Sprintf("index.php/apps/polls/api/v1.0/poll/%d", id), &result)
+	if errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("poll with ID %d not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result.Poll, nil
+}
+
+// getPollVoteCount counts the votes of the poll
+func getPollVoteCount(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	p, ok := h.Item.(poll)
+	if !ok {
+		return nil, nil
+	}
+
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Votes []json.RawMessage `json:"votes"`
+	}
+	if err := client.GetJSON(ctx, fmt.Sprintf("index.php/apps/polls/api/v1.0/poll/%d/votes", p.ID), &result); err != nil {
+		return nil, err
+	}
+	return len(result.Votes), nil
+}
@@ -0,0 +1,54 @@
+package nextcloud
+
+import (
+	"context"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// tableNextcloudQuota defines the schema for per-user storage consumption
+func tableNextcloudQuota() *plugin.Table {
+	return &plugin.Table{
+		Name:        "nextcloud_quota",
+		Description: "Storage quota and consumption of every Nextcloud user (from the Provisioning API). user_id is free, every other column costs one request per user",
+		List: &plugin.ListConfig{
+			Hydrate: listUsersDetailedHydrate,
+			KeyColumns: plugin.KeyColumnSlice{
+				{Name: "user_id", Require: plugin.Optional},
+			},
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "user_id", Type: proto.ColumnType_STRING, Description: "User ID", Transform: transform.FromField("ID")},
+			{Name: "quota_bytes", Type: proto.ColumnType_INT, Description: "Quota of the user in bytes, NULL when unlimited", Hydrate: getUserHydrate, Transform: transform.FromField("Quota").Transform(quotaLimitBytes)},
+			{Name: "unlimited", Type: proto.ColumnType_BOOL, Description: "True if the user has no storage quota", Hydrate: getUserHydrate, Transform: transform.FromField("Quota").Transform(quotaIsUnlimited)},
+			{Name: "used_bytes", Type: proto.ColumnType_INT, Description: "Storage used by the user in bytes", Hydrate: getUserHydrate, Transform: transform.FromField("Quota.Used")},
+			{Name: "free_bytes", Type: proto.ColumnType_INT, Description: "Storage still available to the user in bytes", Hydrate: getUserHydrate, Transform: transform.FromField("Quota.Free")},
+			{Name: "relative_percent", Type: proto.ColumnType_DOUBLE, Description: "Percentage of the quota in use", Hydrate: getUserHydrate, Transform: transform.FromField("Quota.Relative")},
+		}),
+	}
+}
+
+// quotaLimitBytes returns the quota in bytes or nil for the unlimited sentinel
+func quotaLimitBytes(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	q, ok := d.Value.(userQuota)
+	if !ok {
+		return nil, nil
+	}
+	limit, unlimited := q.Limit()
+	if unlimited {
+		return nil, nil
+	}
+	return limit, nil
+}
+
+// quotaIsUnlimited reports whether the user has no storage quota
+func quotaIsUnlimited(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	q, ok := d.Value.(userQuota)
+	if !ok {
+		return nil, nil
+	}
+	_, unlimited := q.Limit()
+	return unlimited, nil
+}
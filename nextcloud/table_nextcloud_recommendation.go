@@ -0,0 +1,76 @@
+package nextcloud
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// recommendation is a file recommended by the Recommendations app
+type recommendation struct {
+	ID        json.Number `json:"id"`
+	Timestamp int64       `json:"timestamp"`
+	Name      string      `json:"name"`
+	Directory string      `json:"directory"`
+	Extension string      `json:"extension"`
+	MimeType  string      `json:"mimeType"`
+	Reason    string      `json:"reason"`
+}
+
+// Path returns the path of the recommended file relative to the user's root
+func (r recommendation) Path() string {
+	return path.Join("/", r.Directory, r.Name)
+}
+
+// tableNextcloudRecommendation defines the schema for the files recommended to the configured user
+func tableNextcloudRecommendation() *plugin.Table {
+	return &plugin.Table{
+		Name:        "nextcloud_recommendation",
+		Description: "Files recommended to the configured user by the Recommendations app (recently edited, shared or commented)",
+		List: &plugin.ListConfig{
+			Hydrate: listIfAppEnabled("recommendations", listRecommendations),
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "id", Type: proto.ColumnType_STRING, Description: "File ID of the recommended file", Transform: transform.FromField("ID")},
+			{Name: "name", Type: proto.ColumnType_STRING, Description: "Name of the file", Transform: transform.FromField("Name")},
+			{Name: "path", Type: proto.ColumnType_STRING, Description: "Path of the file relative to the user's root", Transform: transform.FromMethod("Path")},
+			{Name: "mimetype", Type: proto.ColumnType_STRING, Description: "Mimetype of the file", Transform: transform.FromField("MimeType")},
+			{Name: "timestamp", Type: proto.ColumnType_TIMESTAMP, Description: "Time of the interaction the recommendation is based on", Transform: transform.FromField("Timestamp").Transform(transform.UnixToTimestamp)},
+			{Name: "reason", Type: proto.ColumnType_STRING, Description: "Why the file is recommended (recently-edited, recently-shared, recently-commented...)", Transform: transform.FromField("Reason")},
+		}),
+	}
+}
+
+// listRecommendations streams the recommendations of the configured user
+func listRecommendations(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ocsGetData[json.RawMessage](ctx, client, "ocs/v2.php/apps/recommendations/api/v1/recommendations?format=json")
+	if err != nil {
+		return nil, appNotEnabledError("recommendations", err)
+	}
+
+	// current versions wrap the list with the enabled flag, older ones return it directly
+	var recommendations []recommendation
+	var wrapped struct {
+		Enabled         bool             `json:"enabled"`
+		Recommendations []recommendation `json:"recommendations"`
+	}
+	if err := json.Unmarshal(data, &wrapped); err == nil {
+		recommendations = wrapped.Recommendations
+	} else if err := json.Unmarshal(data, &recommendations); err != nil {
+		return nil, err
+	}
+
+	for _, r := range recommendations {
+		d.StreamListItem(ctx, r)
+	}
+	return nil, nil
+}
@@ -0,0 +1,107 @@
+package nextcloud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// serverInfo represents the monitoring data returned by the serverinfo app
+type serverInfo struct {
+	Nextcloud struct {
+		System struct {
+			Version  string      `json:"version"`
+			CPULoad  interface{} `json:"cpuload"`
+			MemTotal int64       `json:"mem_total"`
+			MemFree  int64       `json:"mem_free"`
+			Apps     struct {
+				NumInstalled        int `json:"num_installed"`
+				NumUpdatesAvailable int `json:"num_updates_available"`
+			} `json:"apps"`
+		} `json:"system"`
+		Storage struct {
+			NumUsers int64 `json:"num_users"`
+			NumFiles int64 `json:"num_files"`
+		} `json:"storage"`
+		Shares struct {
+			NumShares int64 `json:"num_shares"`
+		} `json:"shares"`
+	} `json:"nextcloud"`
+	Server struct {
+		Webserver string `json:"webserver"`
+		PHP       struct {
+			Version string `json:"version"`
+		} `json:"php"`
+		Database struct {
+			Type    string `json:"type"`
+			Version string `json:"version"`
+			Size    int64  `json:"size"`
+		} `json:"database"`
+	} `json:"server"`
+	ActiveUsers struct {
+		Last5Minutes int64 `json:"last5minutes"`
+		Last1Hour    int64 `json:"last1hour"`
+		Last24Hours  int64 `json:"last24hours"`
+	} `json:"activeUsers"`
+}
+
+// tableNextcloudServerInfo defines the schema for the server monitoring metrics (single row)
+func tableNextcloudServerInfo() *plugin.Table {
+	return &plugin.Table{
+		Name:        "nextcloud_server_info",
+		Description: "Nextcloud system metrics from the serverinfo app (requires admin credentials or serverinfo_token)",
+		List: &plugin.ListConfig{
+			Hydrate: listIfAppEnabled("serverinfo", listServerInfo),
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "nextcloud_version", Type: proto.ColumnType_STRING, Description: "Nextcloud version", Transform: transform.FromField("Nextcloud.System.Version")},
+			{Name: "webserver", Type: proto.ColumnType_STRING, Description: "Web server software", Transform: transform.FromField("Server.Webserver")},
+			{Name: "php_version", Type: proto.ColumnType_STRING, Description: "PHP version", Transform: transform.FromField("Server.PHP.Version")},
+			{Name: "database_type", Type: proto.ColumnType_STRING, Description: "Database type (mysql, pgsql, sqlite...)", Transform: transform.FromField("Server.Database.Type")},
+			{Name: "database_version", Type: proto.ColumnType_STRING, Description: "Database version", Transform: transform.FromField("Server.Database.Version")},
+			{Name: "database_size", Type: proto.ColumnType_INT, Description: "Database size in bytes", Transform: transform.FromField("Server.Database.Size")},
+			{Name: "cpu_load", Type: proto.ColumnType_JSON, Description: "CPU load averages (1, 5 and 15 minutes)", Transform: transform.FromField("Nextcloud.System.CPULoad")},
+			{Name: "memory_total", Type: proto.ColumnType_INT, Description: "Total memory in kB", Transform: transform.FromField("Nextcloud.System.MemTotal")},
+			{Name: "memory_free", Type: proto.ColumnType_INT, Description: "Free memory in kB", Transform: transform.FromField("Nextcloud.System.MemFree")},
+			{Name: "num_users", Type: proto.ColumnType_INT, Description: "Number of users", Transform: transform.FromField("Nextcloud.Storage.NumUsers")},
+			{Name: "num_files", Type: proto.ColumnType_INT, Description: "Number of files", Transform: transform.FromField("Nextcloud.Storage.NumFiles")},
+			{Name: "num_shares", Type: proto.ColumnType_INT, Description: "Number of shares", Transform: transform.FromField("Nextcloud.Shares.NumShares")},
+			{Name: "active_users_last5min", Type: proto.ColumnType_INT, Description: "Users active in the last 5 minutes", Transform: transform.FromField("ActiveUsers.Last5Minutes")},
+			{Name: "active_users_last1hour", Type: proto.ColumnType_INT, Description: "Users active in the last hour", Transform: transform.FromField("ActiveUsers.Last1Hour")},
+			{Name: "active_users_last24hours", Type: proto.ColumnType_INT, Description: "Users active in the last 24 hours", Transform: transform.FromField("ActiveUsers.Last24Hours")},
+			{Name: "apps_num_installed", Type: proto.ColumnType_INT, Description: "Number of installed apps", Transform: transform.FromField("Nextcloud.System.Apps.NumInstalled")},
+			{Name: "apps_updates_available", Type: proto.ColumnType_INT, Description: "Number of apps with an update available", Transform: transform.FromField("Nextcloud.System.Apps.NumUpdatesAvailable")},
+		}),
+	}
+}
+
+// listServerInfo retrieves the serverinfo metrics and streams them as a single row
+func listServerInfo(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := "ocs/v2.php/apps/serverinfo/api/v1/info?format=json"
+	resp, err := client.MakeServerInfoRequest(ctx, endpoint)
+	if err != nil {
+		var httpErr *HTTPError
+		if errors.As(err, &httpErr) && (httpErr.StatusCode == http.StatusUnauthorized || httpErr.StatusCode == http.StatusForbidden) {
+			return nil, fmt.Errorf("access to the serverinfo API was denied (HTTP %d): it requires admin credentials or a valid serverinfo_token", httpErr.StatusCode)
+		}
+		return nil, appNotEnabledError("serverinfo", ocsErrorFromHTTP(err))
+	}
+	defer resp.Body.Close()
+
+	info, err := ocsDecode[serverInfo](resp, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	d.StreamListItem(ctx, info)
+	return nil, nil
+}
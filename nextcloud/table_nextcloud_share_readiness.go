@@ -0,0 +1,161 @@
+package nextcloud
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// Share types of the Files Sharing API
+const (
+	shareTypeUser      = 0
+	shareTypeGroup     = 1
+	shareTypePublic    = 3
+	shareTypeEmail     = 4
+	shareTypeFederated = 6
+	shareTypeCircle    = 7
+)
+
+// sharePolicy is the sharing policy of the server, read from the files_sharing capabilities
+type sharePolicy struct {
+	APIEnabled         bool
+	GroupSharing       bool
+	PublicEnabled      bool
+	PasswordEnforced   bool
+	ExpireEnforced     bool
+	MaxExpireDays      int64
+	ShareByMail        bool
+	FederationOutgoing bool
+}
+
+// sharePolicyFromCapabilities extracts the sharing policy from the capabilities
+func sharePolicyFromCapabilities(caps *ncCapabilities) sharePolicy {
+	policy := sharePolicy{
+		APIEnabled:         caps.Bool("files_sharing", "api_enabled"),
+		GroupSharing:       caps.Bool("files_sharing", "group_sharing"),
+		PublicEnabled:      caps.Bool("files_sharing", "public", "enabled"),
+		PasswordEnforced:   caps.Bool("files_sharing", "public", "password", "enforced"),
+		ExpireEnforced:     caps.Bool("files_sharing", "public", "expire_date", "enforced"),
+		ShareByMail:        caps.Bool("files_sharing", "sharebymail", "enabled"),
+		FederationOutgoing: caps.Bool("files_sharing", "federation", "outgoing"),
+	}
+	if policy.ExpireEnforced {
+		policy.MaxExpireDays = caps.Int("files_sharing", "public", "expire_date", "days")
+	}
+	return policy
+}
+
+// shareReadiness is a row of nextcloud_share_readiness
+type shareReadiness struct {
+	Path               string
+	Permissions        string
+	SharingAllowed     bool
+	AllowedShareTypes  []int
+	PublicLinksAllowed bool
+	PasswordRequired   bool
+	ExpireEnforced     bool
+	MaxExpireDays      int64
+	ExistingShareTypes []int
+	ExistingShareCount int
+}
+
+// tableNextcloudShareReadiness defines the schema for checking whether a path can be shared, and how
+func tableNextcloudShareReadiness() *plugin.Table {
+	return &plugin.Table{
+		Name:        "nextcloud_share_readiness",
+		Description: "Whether a path of the configured user can be shared and under which policy, from the capabilities, the permissions of the path and its existing shares (requires a path qualifier)",
+		List: &plugin.ListConfig{
+			Hydrate:    listShareReadiness,
+			KeyColumns: plugin.SingleColumn("path"),
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "path", Type: proto.ColumnType_STRING, Description: "Path to check, relative to the user's root", Transform: transform.FromField("Path")},
+			{Name: "permissions", Type: proto.ColumnType_STRING, Description: "WebDAV permission letters of the path; R means it can be shared", Transform: transform.FromField("Permissions")},
+			{Name: "sharing_allowed", Type: proto.ColumnType_BOOL, Description: "True if the sharing API is enabled and the user may share the path", Transform: transform.FromField("SharingAllowed")},
+			{Name: "allowed_share_types", Type: proto.ColumnType_JSON, Description: "Share types that can be created on the path (0 user, 1 group, 3 public link, 4 email, 6 federated)", Transform: transform.FromField("AllowedShareTypes")},
+			{Name: "public_links_allowed", Type: proto.ColumnType_BOOL, Description: "True if a public link can be created on the path", Transform: transform.FromField("PublicLinksAllowed")},
+			{Name: "password_required", Type: proto.ColumnType_BOOL, Description: "True if public links must be protected by a password", Transform: transform.FromField("PasswordRequired")},
+			{Name: "expire_enforced", Type: proto.ColumnType_BOOL, Description: "True if public links must have an expiration date", Transform: transform.FromField("ExpireEnforced")},
+			{Name: "max_expire_days", Type: proto.ColumnType_INT, Description: "Maximum lifetime of a public link in days, NULL when the expiration is not enforced", Transform: transform.FromField("MaxExpireDays").NullIfZero()},
+			{Name: "existing_share_types", Type: proto.ColumnType_JSON, Description: "Share types of the shares already created on the path", Transform: transform.FromField("ExistingShareTypes")},
+			{Name: "existing_share_count", Type: proto.ColumnType_INT, Description: "Number of shares already created on the path", Transform: transform.FromField("ExistingShareCount")},
+		}),
+	}
+}
+
+// listShareReadiness combines the sharing policy, the permissions of the path and its shares into a single row
+func listShareReadiness(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	filePath := cleanDAVPath(d.EqualsQuals["path"].GetStringValue())
+
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	caps, err := getCapabilities(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	policy := sharePolicyFromCapabilities(caps)
+
+	responses, err := davQuery[davFileProp](ctx, client, "PROPFIND", davFilesEndpoint(client.Username, filePath), "0", filePropfindBody)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the permissions of %s: %w", filePath, err)
+	}
+	row := shareReadiness{
+		Path:               filePath,
+		PasswordRequired:   policy.PasswordEnforced,
+		ExpireEnforced:     policy.ExpireEnforced,
+		MaxExpireDays:      policy.MaxExpireDays,
+		AllowedShareTypes:  []int{},
+		ExistingShareTypes: []int{},
+	}
+	if len(responses) > 0 {
+		if file, ok := fileFromDAV(client.Username, responses[0]); ok {
+			row.Permissions = file.Permissions
+		}
+	}
+
+	row.SharingAllowed = policy.APIEnabled && strings.Contains(row.Permissions, "R")
+	if row.SharingAllowed {
+		row.AllowedShareTypes = append(row.AllowedShareTypes, shareTypeUser)
+		if policy.GroupSharing {
+			row.AllowedShareTypes = append(row.AllowedShareTypes, shareTypeGroup)
+		}
+		if policy.PublicEnabled {
+			row.PublicLinksAllowed = true
+			row.AllowedShareTypes = append(row.AllowedShareTypes, shareTypePublic)
+		}
+		if policy.ShareByMail {
+			row.AllowedShareTypes = append(row.AllowedShareTypes, shareTypeEmail)
+		}
+		if policy.FederationOutgoing {
+			row.AllowedShareTypes = append(row.AllowedShareTypes, shareTypeFederated)
+		}
+	}
+
+	if policy.APIEnabled {
+		params := url.Values{"format": {"json"}, "path": {filePath}, "reshares": {"true"}}
+		shares, err := ocsGet[ocsShare](ctx, client, "ocs/v2.php/apps/files_sharing/api/v1/shares?"+params.Encode())
+		if err != nil {
+			return nil, err
+		}
+		seen := map[int]bool{}
+		for _, share := range shares {
+			if !seen[share.ShareType] {
+				seen[share.ShareType] = true
+				row.ExistingShareTypes = append(row.ExistingShareTypes, share.ShareType)
+			}
+		}
+		sort.Ints(row.ExistingShareTypes)
+		row.ExistingShareCount = len(shares)
+	}
+
+	d.StreamListItem(ctx, row)
+	return nil, nil
+}
@@ -0,0 +1,128 @@
+package nextcloud
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"sort"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// shareeCandidate is a single recipient suggestion of the sharees API
+type shareeCandidate struct {
+	Label string `json:"label"`
+	Value struct {
+		ShareType int    `json:"shareType"`
+		ShareWith string `json:"shareWith"`
+		Server    string `json:"server"`
+	} `json:"value"`
+}
+
+// sharee is a row of the nextcloud_sharee table
+type sharee struct {
+	Search    string
+	ItemType  string
+	Label     string
+	ShareType int
+	ShareWith string
+	Server    string
+	Source    string
+	Exact     bool
+}
+
+// tableNextcloudSharee defines the schema for share recipient search
+func tableNextcloudSharee() *plugin.Table {
+	return &plugin.Table{
+		Name:        "nextcloud_sharee",
+		Description: "Share recipients the sharing autocomplete resolves for a search term (requires a search qualifier)",
+		List: &plugin.ListConfig{
+			Hydrate: listSharees,
+			KeyColumns: plugin.KeyColumnSlice{
+				{Name: "search", Require: plugin.Required},
+				{Name: "item_type", Require: plugin.Optional},
+			},
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "search", Type: proto.ColumnType_STRING, Description: "Search term", Transform: transform.FromField("Search")},
+			{Name: "item_type", Type: proto.ColumnType_STRING, Description: "Type of item to share (file or folder), defaults to file", Transform: transform.FromField("ItemType")},
+			{Name: "label", Type: proto.ColumnType_STRING, Description: "Label shown for the candidate", Transform: transform.FromField("Label")},
+			{Name: "share_type", Type: proto.ColumnType_INT, Description: "Share type the candidate would get (0=user, 1=group, 4=email, 6=remote, 7=circle)", Transform: transform.FromField("ShareType")},
+			{Name: "share_with", Type: proto.ColumnType_STRING, Description: "Value to share with (user ID, group ID, email, federated cloud ID...)", Transform: transform.FromField("ShareWith")},
+			{Name: "server", Type: proto.ColumnType_STRING, Description: "Remote server of federated candidates", Transform: transform.FromField("Server").NullIfZero()},
+			{Name: "source", Type: proto.ColumnType_STRING, Description: "Category the candidate comes from (users, groups, remotes, emails, circles...)", Transform: transform.FromField("Source")},
+			{Name: "exact", Type: proto.ColumnType_BOOL, Description: "True if the candidate is an exact match of the search term", Transform: transform.FromField("Exact")},
+		}),
+	}
+}
+
+// listSharees runs a sharees search and flattens the exact and regular result groups into rows
+func listSharees(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	search := d.EqualsQuals["search"].GetStringValue()
+	itemType := "file"
+	if qual := d.EqualsQuals["item_type"]; qual != nil {
+		itemType = qual.GetStringValue()
+	}
+
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	params := url.Values{}
+	params.Set("format", "json")
+	params.Set("search", search)
+	params.Set("itemType", itemType)
+	endpoint := "ocs/v2.php/apps/files_sharing/api/v1/sharees?" + params.Encode()
+
+	// data holds one array per source plus an "exact" object with the same layout
+	data, err := ocsGetData[map[string]json.RawMessage](ctx, client, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	base := sharee{Search: search, ItemType: itemType}
+	if raw, ok := data["exact"]; ok {
+		var exact map[string]json.RawMessage
+		if json.Unmarshal(raw, &exact) == nil {
+			streamSharees(ctx, d, base, exact, true)
+		}
+	}
+	if d.RowsRemaining(ctx) == 0 {
+		return nil, nil
+	}
+	delete(data, "exact")
+	streamSharees(ctx, d, base, data, false)
+	return nil, nil
+}
+
+// streamSharees streams the candidates of every source array in groups
+func streamSharees(ctx context.Context, d *plugin.QueryData, base sharee, groups map[string]json.RawMessage, exact bool) {
+	sources := make([]string, 0, len(groups))
+	for source := range groups {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	for _, source := range sources {
+		var candidates []shareeCandidate
+		// sources that are not arrays of candidates (e.g. "lookupEnabled") are skipped
+		if json.Unmarshal(groups[source], &candidates) != nil {
+			continue
+		}
+		for _, c := range candidates {
+			row := base
+			row.Label = c.Label
+			row.ShareType = c.Value.ShareType
+			row.ShareWith = c.Value.ShareWith
+			row.Server = c.Value.Server
+			row.Source = source
+			row.Exact = exact
+			d.StreamListItem(ctx, row)
+			if d.RowsRemaining(ctx) == 0 {
+				return
+			}
+		}
+	}
+}
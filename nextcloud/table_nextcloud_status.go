@@ -0,0 +1,111 @@
+package nextcloud
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// serverStatus is the document returned by status.php
+type serverStatus struct {
+	Installed       bool   `json:"installed"`
+	Maintenance     bool   `json:"maintenance"`
+	NeedsDBUpgrade  bool   `json:"needsDbUpgrade"`
+	Version         string `json:"version"`
+	VersionString   string `json:"versionstring"`
+	Edition         string `json:"edition"`
+	ProductName     string `json:"productname"`
+	ExtendedSupport bool   `json:"extendedSupport"`
+}
+
+// Healthy reports whether the server is installed and serving requests
+func (s serverStatus) Healthy() bool {
+	return s.Installed && !s.Maintenance && !s.NeedsDBUpgrade
+}
+
+// Major returns the major version number, nil if the version can't be parsed
+func (s serverStatus) Major() *int {
+	return s.versionPart(0)
+}
+
+// Minor returns the minor version number, nil if the version can't be parsed
+func (s serverStatus) Minor() *int {
+	return s.versionPart(1)
+}
+
+// Micro returns the micro (patch) version number, nil if the version can't be parsed
+func (s serverStatus) Micro() *int {
+	return s.versionPart(2)
+}
+
+// versionPart returns the i-th dot separated number of Version (e.g. 28.0.1.1)
+func (s serverStatus) versionPart(i int) *int {
+	parts := strings.Split(s.Version, ".")
+	if i >= len(parts) {
+		return nil
+	}
+	n, err := strconv.Atoi(parts[i])
+	if err != nil {
+		return nil
+	}
+	return &n
+}
+
+// tableNextcloudStatus defines the schema for the public status of the server (single row)
+func tableNextcloudStatus() *plugin.Table {
+	return &plugin.Table{
+		Name:        "nextcloud_status",
+		Description: "Public status of the server from status.php, fetched without authentication (cheapest health check)",
+		List: &plugin.ListConfig{
+			Hydrate: listStatus,
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "installed", Type: proto.ColumnType_BOOL, Description: "True if Nextcloud is installed", Transform: transform.FromField("Installed")},
+			{Name: "maintenance", Type: proto.ColumnType_BOOL, Description: "True if the server is in maintenance mode", Transform: transform.FromField("Maintenance")},
+			{Name: "needs_db_upgrade", Type: proto.ColumnType_BOOL, Description: "True if the database must be upgraded (occ upgrade) before the server can be used", Transform: transform.FromField("NeedsDBUpgrade")},
+			{Name: "version", Type: proto.ColumnType_STRING, Description: "Full version number (e.g. 28.0.1.1)", Transform: transform.FromField("Version")},
+			{Name: "version_string", Type: proto.ColumnType_STRING, Description: "Human readable version (e.g. 28.0.1)", Transform: transform.FromField("VersionString")},
+			{Name: "edition", Type: proto.ColumnType_STRING, Description: "Edition of the server, empty for the community edition", Transform: transform.FromField("Edition").NullIfZero()},
+			{Name: "product_name", Type: proto.ColumnType_STRING, Description: "Product name, as set by theming", Transform: transform.FromField("ProductName")},
+			{Name: "healthy", Type: proto.ColumnType_BOOL, Description: "True if the server is installed, not in maintenance and does not need a database upgrade", Transform: transform.FromMethod("Healthy")},
+			{Name: "extended_support", Type: proto.ColumnType_BOOL, Description: "True if the server runs under an extended support subscription", Transform: transform.FromField("ExtendedSupport")},
+		}),
+	}
+}
+
+// listStatus fetches status.php without testing the OCS connection first, so
+// that it also answers while the server is in maintenance
+func listStatus(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	client, err := GetUntestedClient(ctx, d.Connection)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := fetchServerStatus(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	d.StreamListItem(ctx, status)
+	return nil, nil
+}
+
+// fetchServerStatus fetches and decodes status.php (unauthenticated)
+func fetchServerStatus(ctx context.Context, client *NextcloudClient) (serverStatus, error) {
+	var status serverStatus
+	if err := client.GetRawJSON(ctx, "status.php", false, &status); err != nil {
+		// some setups answer 503 while in maintenance, still with the status document
+		var httpErr *HTTPError
+		if !errors.As(err, &httpErr) || httpErr.StatusCode != 503 || json.Unmarshal([]byte(httpErr.Body), &status) != nil {
+			return serverStatus{}, fmt.Errorf("error fetching Nextcloud status: %w", err)
+		}
+	}
+	return status, nil
+}
@@ -0,0 +1,109 @@
+package nextcloud
+
+import (
+	"context"
+	"sort"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// storageOverviewTopConsumers is the number of users listed in largest_consumers
+const storageOverviewTopConsumers = 10
+
+// storageConsumer is an entry of largest_consumers
+type storageConsumer struct {
+	UserID    string `json:"user_id"`
+	UsedBytes int64  `json:"used_bytes"`
+}
+
+// storageOverview is the single row of nextcloud_storage_overview
+type storageOverview struct {
+	UserCount          int
+	FailedUserCount    int
+	TotalUsedBytes     int64
+	TotalQuotaBytes    int64
+	UnlimitedUserCount int
+	OverQuotaUserCount int
+	AverageUsedPercent float64
+	LargestConsumers   []storageConsumer
+
+	// sum of the relative usage of the users with a quota, for the average
+	relativeSum float64
+}
+
+// add accounts for the quota of a user. Only the running totals and the
+// largest consumers are kept, so memory does not grow with the number of users.
+func (o *storageOverview) add(user *ncUser) {
+	o.UserCount++
+	used := int64(user.Quota.Used)
+	o.TotalUsedBytes += used
+
+	if limit, unlimited := user.Quota.Limit(); unlimited {
+		o.UnlimitedUserCount++
+	} else {
+		o.TotalQuotaBytes += limit
+		o.relativeSum += user.Quota.Relative
+		if used > limit {
+			o.OverQuotaUserCount++
+		}
+	}
+
+	o.LargestConsumers = append(o.LargestConsumers, storageConsumer{UserID: user.ID, UsedBytes: used})
+	sort.SliceStable(o.LargestConsumers, func(i, j int) bool {
+		return o.LargestConsumers[i].UsedBytes > o.LargestConsumers[j].UsedBytes
+	})
+	if len(o.LargestConsumers) > storageOverviewTopConsumers {
+		o.LargestConsumers = o.LargestConsumers[:storageOverviewTopConsumers]
+	}
+}
+
+// tableNextcloudStorageOverview defines the schema for the storage totals across every user (single row)
+func tableNextcloudStorageOverview() *plugin.Table {
+	return &plugin.Table{
+		Name:        "nextcloud_storage_overview",
+		Description: "Storage consumption totals across every user (from the Provisioning API, requires admin credentials). Costs one request per user",
+		List: &plugin.ListConfig{
+			Hydrate: listStorageOverview,
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "user_count", Type: proto.ColumnType_INT, Description: "Number of users accounted for", Transform: transform.FromField("UserCount")},
+			{Name: "failed_user_count", Type: proto.ColumnType_INT, Description: "Number of users whose details could not be fetched, left out of the totals", Transform: transform.FromField("FailedUserCount")},
+			{Name: "total_used_bytes", Type: proto.ColumnType_INT, Description: "Storage used by all users, in bytes", Transform: transform.FromField("TotalUsedBytes")},
+			{Name: "total_quota_bytes", Type: proto.ColumnType_INT, Description: "Sum of the quotas of the users with a quota, in bytes", Transform: transform.FromField("TotalQuotaBytes")},
+			{Name: "unlimited_user_count", Type: proto.ColumnType_INT, Description: "Number of users without a storage quota", Transform: transform.FromField("UnlimitedUserCount")},
+			{Name: "over_quota_user_count", Type: proto.ColumnType_INT, Description: "Number of users using more than their quota", Transform: transform.FromField("OverQuotaUserCount")},
+			{Name: "average_used_percent", Type: proto.ColumnType_DOUBLE, Description: "Average percentage of the quota in use, across the users with a quota", Transform: transform.FromField("AverageUsedPercent")},
+			{Name: "largest_consumers", Type: proto.ColumnType_JSON, Description: "Users using the most storage (user_id, used_bytes), largest first", Transform: transform.FromField("LargestConsumers")},
+		}),
+	}
+}
+
+// listStorageOverview aggregates the quota of every user as their details arrive
+func listStorageOverview(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := listUserIDs(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	overview := storageOverview{LargestConsumers: []storageConsumer{}}
+	forEachUserDetailed(ctx, client, ids, maxConcurrency(d.Connection), func(user *ncUser) bool {
+		overview.add(user)
+		return true
+	})
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	overview.FailedUserCount = len(ids) - overview.UserCount
+	if withQuota := overview.UserCount - overview.UnlimitedUserCount; withQuota > 0 {
+		overview.AverageUsedPercent = overview.relativeSum / float64(withQuota)
+	}
+	d.StreamListItem(ctx, overview)
+	return nil, nil
+}
@@ -0,0 +1,127 @@
+package nextcloud
+
+import (
+	"context"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// storageQuotaPropfindBody requests the usage of a folder
+const storageQuotaPropfindBody = `<?xml version="1.0" encoding="utf-8"?>
+<d:propfind xmlns:d="DAV:">
+  <d:prop>
+    <d:quota-used-bytes/>
+    <d:quota-available-bytes/>
+  </d:prop>
+</d:propfind>`
+
+// davQuotaProp holds the usage properties of a folder
+type davQuotaProp struct {
+	UsedBytes      string `xml:"DAV: quota-used-bytes"`
+	AvailableBytes string `xml:"DAV: quota-available-bytes"`
+}
+
+// externalMount is a mount of the External storage app visible to the configured user
+type externalMount struct {
+	ID      int64  `json:"id"`
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Backend string `json:"backend"`
+	Scope   string `json:"scope"`
+}
+
+// storageStat is a row of the nextcloud_storage_stat table
+type storageStat struct {
+	MountID        int64
+	MountPoint     string
+	Backend        string
+	Scope          string
+	UsedBytes      *int64
+	AvailableBytes *int64
+	Status         string
+	Error          string
+	LastChecked    time.Time
+}
+
+// tableNextcloudStorageStat defines the schema for the usage and status of every external storage mount
+func tableNextcloudStorageStat() *plugin.Table {
+	return &plugin.Table{
+		Name:        "nextcloud_storage_stat",
+		Description: "Usage and status of the external storage mounts of the configured user (files_external app)",
+		List: &plugin.ListConfig{
+			Hydrate: listIfAppEnabled("files_external", listStorageStats),
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "mount_id", Type: proto.ColumnType_INT, Description: "ID of the mount", Transform: transform.FromField("MountID")},
+			{Name: "mount_point", Type: proto.ColumnType_STRING, Description: "Path of the mount relative to the user's root", Transform: transform.FromField("MountPoint")},
+			{Name: "backend", Type: proto.ColumnType_STRING, Description: "Storage backend (SMB, S3, SFTP...)", Transform: transform.FromField("Backend")},
+			{Name: "scope", Type: proto.ColumnType_STRING, Description: "Scope of the mount (system or personal)", Transform: transform.FromField("Scope")},
+			{Name: "used_bytes", Type: proto.ColumnType_INT, Description: "Space used on the mount in bytes", Transform: transform.FromField("UsedBytes")},
+			{Name: "available_bytes", Type: proto.ColumnType_INT, Description: "Space still available on the mount in bytes, NULL when unknown or unlimited", Transform: transform.FromField("AvailableBytes")},
+			{Name: "status", Type: proto.ColumnType_STRING, Description: "ok if the mount answered, error otherwise", Transform: transform.FromField("Status")},
+			{Name: "error", Type: proto.ColumnType_STRING, Description: "Error returned when accessing the mount", Transform: transform.FromField("Error").NullIfZero()},
+			{Name: "last_checked", Type: proto.ColumnType_TIMESTAMP, Description: "Time the mount was checked (the time of the query)", Transform: transform.FromField("LastChecked")},
+		}),
+	}
+}
+
+// listStorageStats lists the mounts and checks each of them with a Depth: 0
+// PROPFIND returning its usage. A failing mount is reported with its error
+// rather than failing the whole query.
+func listStorageStats(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	mounts, err := ocsGet[externalMount](ctx, client, "ocs/v2.php/apps/files_external/api/v1/mounts?format=json")
+	if err != nil {
+		return nil, appNotEnabledError("files_external", err)
+	}
+
+	for _, mount := range mounts {
+		stat := storageStat{
+			MountID:     mount.ID,
+			MountPoint:  path.Join("/", mount.Path, mount.Name),
+			Backend:     mount.Backend,
+			Scope:       mount.Scope,
+			Status:      "ok",
+			LastChecked: time.Now(),
+		}
+
+		endpoint := davFilesEndpoint(client.Username, stat.MountPoint)
+		responses, err := davQuery[davQuotaProp](ctx, client, "PROPFIND", endpoint, "0", storageQuotaPropfindBody)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			stat.Status = "error"
+			stat.Error = err.Error()
+		} else if len(responses) > 0 {
+			if prop, ok := responses[0].OKProp(); ok {
+				stat.UsedBytes = parseQuotaBytes(prop.UsedBytes)
+				stat.AvailableBytes = parseQuotaBytes(prop.AvailableBytes)
+			}
+		}
+
+		d.StreamListItem(ctx, stat)
+		if d.RowsRemaining(ctx) == 0 {
+			return nil, nil
+		}
+	}
+	return nil, nil
+}
+
+// parseQuotaBytes parses a quota property; negative values (unknown, unlimited) are nil
+func parseQuotaBytes(value string) *int64 {
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return nil
+	}
+	return &n
+}
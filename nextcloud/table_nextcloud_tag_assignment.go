@@ -0,0 +1,85 @@
+package nextcloud
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// tagFilterReportBody is the oc:filter-files REPORT selecting the files carrying a system tag
+const tagFilterReportBody = `<?xml version="1.0" encoding="utf-8"?>
+<oc:filter-files xmlns:d="DAV:" xmlns:oc="http://owncloud.org/ns">
+  <d:prop>
+    <d:resourcetype/>
+    <oc:fileid/>
+  </d:prop>
+  <oc:filter-rules>
+    <oc:systemtag>%d</oc:systemtag>
+  </oc:filter-rules>
+</oc:filter-files>`
+
+// tagAssignment is a row of the nextcloud_tag_assignment table
+type tagAssignment struct {
+	TagID int64
+	ncFile
+}
+
+// tableNextcloudTagAssignment defines the schema for the files carrying a system tag
+func tableNextcloudTagAssignment() *plugin.Table {
+	return &plugin.Table{
+		Name:        "nextcloud_tag_assignment",
+		Description: "Files of the configured user (or of as_user for admins) assigned a collaborative (system) tag (requires a tag_id qualifier)",
+		List: &plugin.ListConfig{
+			Hydrate: listTagAssignments,
+			KeyColumns: plugin.KeyColumnSlice{
+				{Name: "tag_id", Require: plugin.Required},
+				{Name: "as_user", Require: plugin.Optional},
+			},
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "tag_id", Type: proto.ColumnType_INT, Description: "ID of the system tag", Transform: transform.FromField("TagID")},
+			{Name: "as_user", Type: proto.ColumnType_STRING, Description: "User whose files are read (defaults to the configured user); requires admin credentials", Transform: transform.FromQual("as_user")},
+			{Name: "file_id", Type: proto.ColumnType_INT, Description: "Nextcloud file ID", Transform: transform.FromField("FileID")},
+			{Name: "path", Type: proto.ColumnType_STRING, Description: "Path of the item relative to the user's root", Transform: transform.FromField("Path")},
+			{Name: "name", Type: proto.ColumnType_STRING, Description: "Name of the item", Transform: transform.FromField("Name")},
+			{Name: "is_dir", Type: proto.ColumnType_BOOL, Description: "True if the item is a folder", Transform: transform.FromField("IsDir")},
+		}),
+	}
+}
+
+// listTagAssignments runs the filter-files REPORT on the user's files root
+func listTagAssignments(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	qual := d.EqualsQuals["tag_id"]
+	if qual == nil {
+		return nil, fmt.Errorf("tag_id qualifier not provided")
+	}
+	tagID := qual.GetInt64Value()
+
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := davTargetUser(ctx, d, client)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := davFilesEndpoint(user, "/")
+	body := fmt.Sprintf(tagFilterReportBody, tagID)
+	err = davStream(ctx, client, "REPORT", endpoint, "", body, func(r davResponse[davFileProp]) bool {
+		file, ok := fileFromDAV(user, r)
+		if !ok {
+			return true
+		}
+		d.StreamListItem(ctx, tagAssignment{TagID: tagID, ncFile: file})
+		return d.RowsRemaining(ctx) != 0
+	})
+	if err != nil {
+		return nil, asUserError(client, user, err)
+	}
+	return nil, nil
+}
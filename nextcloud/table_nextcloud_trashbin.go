@@ -0,0 +1,165 @@
+package nextcloud
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// defaultTrashbinRetentionDays matches the "auto" retention of the server,
+// which purges deleted files after 30 days
+const defaultTrashbinRetentionDays = 30
+
+// trashbinPropfindBody lists the properties requested for every deleted item
+const trashbinPropfindBody = `<?xml version="1.0" encoding="utf-8"?>
+<d:propfind xmlns:d="DAV:" xmlns:oc="http://owncloud.org/ns" xmlns:nc="http://nextcloud.org/ns">
+  <d:prop>
+    <nc:trashbin-filename/>
+    <nc:trashbin-original-location/>
+    <nc:trashbin-deletion-time/>
+    <d:getcontenttype/>
+    <d:getcontentlength/>
+    <d:resourcetype/>
+    <oc:fileid/>
+    <oc:size/>
+  </d:prop>
+</d:propfind>`
+
+// davTrashbinProp holds the WebDAV properties of a deleted item
+type davTrashbinProp struct {
+	Filename         string `xml:"http://nextcloud.org/ns trashbin-filename"`
+	OriginalLocation string `xml:"http://nextcloud.org/ns trashbin-original-location"`
+	DeletionTime     string `xml:"http://nextcloud.org/ns trashbin-deletion-time"`
+	ContentType      string `xml:"DAV: getcontenttype"`
+	ContentLength    string `xml:"DAV: getcontentlength"`
+	ResourceType     struct {
+		Collection *struct{} `xml:"DAV: collection"`
+	} `xml:"DAV: resourcetype"`
+	FileID string `xml:"http://owncloud.org/ns fileid"`
+	Size   string `xml:"http://owncloud.org/ns size"`
+}
+
+// trashbinItem is a row of the nextcloud_trashbin table
+type trashbinItem struct {
+	TrashName        string
+	Name             string
+	OriginalLocation string
+	DeletionTime     time.Time
+	IsDir            bool
+	Size             int64
+	ContentType      string
+	FileID           int64
+	RetentionDays    int
+}
+
+// DaysUntilAutoDelete returns the number of days left before the item is
+// purged, 0 when it is due, or nil when the retention is unlimited
+func (t trashbinItem) DaysUntilAutoDelete() interface{} {
+	if t.RetentionDays <= 0 || t.DeletionTime.IsZero() {
+		return nil
+	}
+	left := time.Until(t.DeletionTime.AddDate(0, 0, t.RetentionDays))
+	if left <= 0 {
+		return 0
+	}
+	return int(math.Ceil(left.Hours() / 24))
+}
+
+// tableNextcloudTrashbin defines the schema for the deleted files of the configured user
+func tableNextcloudTrashbin() *plugin.Table {
+	return &plugin.Table{
+		Name:        "nextcloud_trashbin",
+		Description: "Deleted files and folders of the configured user, or of as_user for admins (files_trashbin app, WebDAV)",
+		List: &plugin.ListConfig{
+			Hydrate: listIfAppEnabled("files_trashbin", listTrashbin),
+			KeyColumns: plugin.KeyColumnSlice{
+				{Name: "as_user", Require: plugin.Optional},
+			},
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "as_user", Type: proto.ColumnType_STRING, Description: "User whose deleted files are read (defaults to the configured user); requires admin credentials", Transform: transform.FromQual("as_user")},
+			{Name: "trash_name", Type: proto.ColumnType_STRING, Description: "Name of the item in the trash bin (name.d<deletion timestamp>)", Transform: transform.FromField("TrashName")},
+			{Name: "name", Type: proto.ColumnType_STRING, Description: "Name of the item before deletion", Transform: transform.FromField("Name")},
+			{Name: "original_location", Type: proto.ColumnType_STRING, Description: "Path the item is restored to", Transform: transform.FromField("OriginalLocation")},
+			{Name: "deletion_time", Type: proto.ColumnType_TIMESTAMP, Description: "Time the item was deleted", Transform: transform.FromField("DeletionTime").NullIfZero()},
+			{Name: "is_dir", Type: proto.ColumnType_BOOL, Description: "True if the item is a folder", Transform: transform.FromField("IsDir")},
+			{Name: "size", Type: proto.ColumnType_INT, Description: "Size in bytes", Transform: transform.FromField("Size")},
+			{Name: "content_type", Type: proto.ColumnType_STRING, Description: "Mimetype of the file", Transform: transform.FromField("ContentType").NullIfZero()},
+			{Name: "file_id", Type: proto.ColumnType_INT, Description: "Nextcloud file ID", Transform: transform.FromField("FileID").NullIfZero()},
+			{Name: "days_until_auto_delete", Type: proto.ColumnType_INT, Description: "Days left before the item is purged, per the trashbin_retention_days connection option (the server retention is not exposed by its API); NULL when the retention is unlimited. The server may purge earlier when space runs out", Transform: transform.FromMethod("DaysUntilAutoDelete")},
+		}),
+	}
+}
+
+// listTrashbin runs a Depth: 1 PROPFIND on the trash bin of the configured user or of as_user
+func listTrashbin(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	retentionDays := trashbinRetentionDays(d.Connection)
+
+	user, err := davTargetUser(ctx, d, client)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf("remote.php/dav/trashbin/%s/trash/", url.PathEscape(user))
+	err = davStream(ctx, client, "PROPFIND", endpoint, "1", trashbinPropfindBody, func(r davResponse[davTrashbinProp]) bool {
+		prop, ok := r.OKProp()
+		// the trash folder itself has no trashbin-filename
+		if !ok || prop.Filename == "" {
+			return true
+		}
+		item := trashbinItem{
+			TrashName:        trashName(r.Href),
+			Name:             prop.Filename,
+			OriginalLocation: prop.OriginalLocation,
+			IsDir:            prop.ResourceType.Collection != nil,
+			ContentType:      prop.ContentType,
+			RetentionDays:    retentionDays,
+		}
+		if seconds, err := strconv.ParseInt(prop.DeletionTime, 10, 64); err == nil && seconds > 0 {
+			item.DeletionTime = time.Unix(seconds, 0)
+		}
+		item.FileID, _ = strconv.ParseInt(prop.FileID, 10, 64)
+		if prop.Size != "" {
+			item.Size, _ = strconv.ParseInt(prop.Size, 10, 64)
+		} else {
+			item.Size, _ = strconv.ParseInt(prop.ContentLength, 10, 64)
+		}
+		d.StreamListItem(ctx, item)
+		return d.RowsRemaining(ctx) != 0
+	})
+	if err != nil {
+		return nil, appNotEnabledError("files_trashbin", asUserError(client, user, err))
+	}
+	return nil, nil
+}
+
+// trashName returns the last segment of the href of a deleted item
+func trashName(href string) string {
+	name := href
+	if unescaped, err := url.PathUnescape(href); err == nil {
+		name = unescaped
+	}
+	name = strings.TrimSuffix(name, "/")
+	return name[strings.LastIndex(name, "/")+1:]
+}
+
+// trashbinRetentionDays returns trashbin_retention_days, or the server default
+func trashbinRetentionDays(conn *plugin.Connection) int {
+	cfg := GetConfig(conn)
+	if cfg.TrashbinRetentionDays != nil {
+		return *cfg.TrashbinRetentionDays
+	}
+	return defaultTrashbinRetentionDays
+}
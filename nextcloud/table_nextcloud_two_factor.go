@@ -0,0 +1,71 @@
+package nextcloud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// twoFactorState is the two-factor authentication state of a user
+type twoFactorState struct {
+	UserID           string
+	Enabled          bool
+	EnabledProviders []string
+}
+
+// tableNextcloudTwoFactor defines the schema for the two-factor authentication state of every user
+func tableNextcloudTwoFactor() *plugin.Table {
+	return &plugin.Table{
+		Name:        "nextcloud_two_factor",
+		Description: "Two-factor authentication state of every Nextcloud user (requires admin credentials and Nextcloud 26 or later)",
+		List: &plugin.ListConfig{
+			Hydrate: listUsersHydrate,
+			KeyColumns: plugin.KeyColumnSlice{
+				{Name: "user_id", Require: plugin.Optional},
+			},
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "user_id", Type: proto.ColumnType_STRING, Description: "User ID", Transform: transform.FromField("ID")},
+			{Name: "two_factor_enabled", Type: proto.ColumnType_BOOL, Description: "True if at least one second factor provider is enabled for the user", Hydrate: getTwoFactorState, Transform: transform.FromField("Enabled")},
+			{Name: "enabled_providers", Type: proto.ColumnType_JSON, Description: "IDs of the second factor providers enabled for the user (totp, u2f...)", Hydrate: getTwoFactorState, Transform: transform.FromField("EnabledProviders")},
+		}),
+	}
+}
+
+// getTwoFactorState retrieves the provider states of the user streamed by listUsersHydrate
+func getTwoFactorState(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	user, ok := h.Item.(*ncUser)
+	if !ok || user.ID == "" {
+		return nil, fmt.Errorf("user_id not provided")
+	}
+
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := "ocs/v2.php/core/twofactor/state?format=json&" + url.Values{"users[]": {user.ID}}.Encode()
+	states, err := ocsGetData[map[string]map[string]bool](ctx, client, endpoint)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, fmt.Errorf("the two-factor state API is not exposed by this server (it requires Nextcloud 26 or later): %w", err)
+		}
+		return nil, err
+	}
+
+	state := twoFactorState{UserID: user.ID, EnabledProviders: []string{}}
+	for provider, enabled := range states[user.ID] {
+		if enabled {
+			state.EnabledProviders = append(state.EnabledProviders, provider)
+		}
+	}
+	sort.Strings(state.EnabledProviders)
+	state.Enabled = len(state.EnabledProviders) > 0
+	return state, nil
+}
@@ -0,0 +1,32 @@
+package nextcloud
+
+import (
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// tableNextcloudUser defines the schema for the Nextcloud user accounts
+func tableNextcloudUser() *plugin.Table {
+	return &plugin.Table{
+		Name:        "nextcloud_user",
+		Description: "Nextcloud user accounts (from the Provisioning API, requires admin or group admin credentials). user_id is free, every other column costs one request per user",
+		List: &plugin.ListConfig{
+			Hydrate: listUsersDetailedHydrate,
+			KeyColumns: plugin.KeyColumnSlice{
+				{Name: "user_id", Require: plugin.Optional},
+			},
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "user_id", Type: proto.ColumnType_STRING, Description: "User ID", Transform: transform.FromField("ID")},
+			{Name: "display_name", Type: proto.ColumnType_STRING, Description: "Display name of the user", Transform: transform.FromField("DisplayName")},
+			{Name: "email", Type: proto.ColumnType_STRING, Description: "Email address of the user", Transform: transform.FromField("Email").NullIfZero()},
+			{Name: "enabled", Type: proto.ColumnType_BOOL, Description: "True if the account is enabled", Transform: transform.FromField("Enabled").Transform(ocsBoolValue)},
+			{Name: "last_login", Type: proto.ColumnType_TIMESTAMP, Description: "Last login time, NULL if the user never logged in", Transform: transform.FromField("LastLogin").Transform(transform.UnixMsToTimestamp)},
+			{Name: "backend", Type: proto.ColumnType_STRING, Description: "User backend the account comes from (Database, LDAP...)", Transform: transform.FromField("Backend").NullIfZero()},
+			{Name: "storage_location", Type: proto.ColumnType_STRING, Description: "Path of the user's home folder on the server", Transform: transform.FromField("StorageLocation").NullIfZero()},
+			{Name: "language", Type: proto.ColumnType_STRING, Description: "Language of the user interface", Transform: transform.FromField("Language").NullIfZero()},
+			{Name: "locale", Type: proto.ColumnType_STRING, Description: "Locale used for dates and numbers", Transform: transform.FromField("Locale").NullIfZero()},
+		}),
+	}
+}
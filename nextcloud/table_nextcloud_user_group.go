@@ -0,0 +1,99 @@
+package nextcloud
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// userGroup is a row of the nextcloud_user_group table
+type userGroup struct {
+	UserID  string
+	GroupID string
+}
+
+// tableNextcloudUserGroup defines the schema for the group memberships of every user
+func tableNextcloudUserGroup() *plugin.Table {
+	return &plugin.Table{
+		Name:        "nextcloud_user_group",
+		Description: "Group memberships of the Nextcloud users, one row per user and group (from the Provisioning API)",
+		List: &plugin.ListConfig{
+			Hydrate: listUserGroups,
+			KeyColumns: plugin.KeyColumnSlice{
+				{Name: "user_id", Require: plugin.Optional},
+				{Name: "group_id", Require: plugin.Optional},
+			},
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "user_id", Type: proto.ColumnType_STRING, Description: "User ID", Transform: transform.FromField("UserID")},
+			{Name: "group_id", Type: proto.ColumnType_STRING, Description: "ID of a group the user belongs to", Transform: transform.FromField("GroupID")},
+		}),
+	}
+}
+
+// listUserGroups streams the groups of every user, or of the user of a
+// "user_id = X" qualifier. A lone "group_id = X" qualifier lists the members
+// of that group instead of scanning every user.
+func listUserGroups(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	groupID := ""
+	if qual := d.EqualsQuals["group_id"]; qual != nil {
+		groupID = qual.GetStringValue()
+	}
+
+	var userIDs []string
+	if qual := d.EqualsQuals["user_id"]; qual != nil {
+		userIDs = []string{qual.GetStringValue()}
+	} else if groupID != "" {
+		members, err := groupMemberIDs(ctx, d, client, groupID)
+		if err != nil {
+			return nil, err
+		}
+		for _, userID := range members {
+			d.StreamListItem(ctx, userGroup{UserID: userID, GroupID: groupID})
+			if d.RowsRemaining(ctx) == 0 {
+				return nil, nil
+			}
+		}
+		return nil, nil
+	} else if userIDs, err = listUserIDs(ctx, client); err != nil {
+		return nil, err
+	}
+
+	for _, userID := range userIDs {
+		groups, err := listUserGroupIDs(ctx, client, userID)
+		if err != nil {
+			return nil, err
+		}
+		for _, group := range groups {
+			if groupID != "" && group != groupID {
+				continue
+			}
+			d.StreamListItem(ctx, userGroup{UserID: userID, GroupID: group})
+			if d.RowsRemaining(ctx) == 0 {
+				return nil, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// listUserGroupIDs returns the IDs of the groups userID belongs to
+func listUserGroupIDs(ctx context.Context, client *NextcloudClient, userID string) ([]string, error) {
+	endpoint := fmt.Sprintf("ocs/v1.php/cloud/users/%s/groups?format=json", url.PathEscape(userID))
+	data, err := ocsGetData[struct {
+		Groups []string `json:"groups"`
+	}](ctx, client, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the groups of user %s: %w", userID, err)
+	}
+	return data.Groups, nil
+}
@@ -0,0 +1,83 @@
+package nextcloud
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// userStatus represents the presence of a user from the User Status API
+type userStatus struct {
+	UserID  string `json:"userId"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Icon    string `json:"icon"`
+	ClearAt int64  `json:"clearAt"`
+}
+
+// tableNextcloudUserStatus defines the schema for user presence
+func tableNextcloudUserStatus() *plugin.Table {
+	return &plugin.Table{
+		Name:        "nextcloud_user_status",
+		Description: "Nextcloud user statuses (online, away, dnd, offline) from the User Status app",
+		List: &plugin.ListConfig{
+			Hydrate: listIfAppEnabled("user_status", listUserStatuses),
+		},
+		Get: &plugin.GetConfig{
+			KeyColumns: plugin.SingleColumn("user_id"),
+			Hydrate:    getUserStatus,
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "user_id", Type: proto.ColumnType_STRING, Description: "User ID", Transform: transform.FromField("UserID")},
+			{Name: "status", Type: proto.ColumnType_STRING, Description: "Status of the user (online, away, dnd, offline)", Transform: transform.FromField("Status")},
+			{Name: "message", Type: proto.ColumnType_STRING, Description: "Custom status message, if any", Transform: transform.FromField("Message").NullIfZero()},
+			{Name: "icon", Type: proto.ColumnType_STRING, Description: "Emoji icon of the custom status, if any", Transform: transform.FromField("Icon").NullIfZero()},
+			{Name: "clear_at", Type: proto.ColumnType_TIMESTAMP, Description: "Time at which the custom status is cleared", Transform: transform.FromField("ClearAt").Transform(transform.UnixToTimestamp)},
+		}),
+	}
+}
+
+// listUserStatuses retrieves the statuses of all users
+func listUserStatuses(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := "ocs/v2.php/apps/user_status/api/v1/statuses?format=json"
+	statuses, err := ocsGet[userStatus](ctx, client, endpoint)
+	if err != nil {
+		return nil, appNotEnabledError("user_status", err)
+	}
+
+	for _, status := range statuses {
+		d.StreamListItem(ctx, status)
+	}
+	return nil, nil
+}
+
+// getUserStatus retrieves the status of a single user
+func getUserStatus(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	qual := d.EqualsQuals["user_id"]
+	if qual == nil {
+		return nil, fmt.Errorf("user_id qualifier not provided")
+	}
+	userID := qual.GetStringValue()
+
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := fmt.Sprintf("ocs/v2.php/apps/user_status/api/v1/statuses/%s?format=json", url.PathEscape(userID))
+	status, err := ocsGetData[userStatus](ctx, client, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if status.UserID == "" {
+		return nil, fmt.Errorf("status of user %s not found", userID)
+	}
+	return status, nil
+}
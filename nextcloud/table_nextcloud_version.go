@@ -0,0 +1,44 @@
+package nextcloud
+
+import (
+	"context"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// tableNextcloudVersion defines the schema for the server version (single row)
+func tableNextcloudVersion() *plugin.Table {
+	return &plugin.Table{
+		Name:        "nextcloud_version",
+		Description: "Version of the server from status.php, fetched without authentication",
+		List: &plugin.ListConfig{
+			Hydrate: listVersion,
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "version", Type: proto.ColumnType_STRING, Description: "Full version number (e.g. 28.0.1.1)", Transform: transform.FromField("Version")},
+			{Name: "version_string", Type: proto.ColumnType_STRING, Description: "Human readable version (e.g. 28.0.1)", Transform: transform.FromField("VersionString")},
+			{Name: "edition", Type: proto.ColumnType_STRING, Description: "Edition of the server, empty for the community edition", Transform: transform.FromField("Edition").NullIfZero()},
+			{Name: "product_name", Type: proto.ColumnType_STRING, Description: "Product name, as set by theming", Transform: transform.FromField("ProductName")},
+			{Name: "major", Type: proto.ColumnType_INT, Description: "Major version number", Transform: transform.FromMethod("Major")},
+			{Name: "minor", Type: proto.ColumnType_INT, Description: "Minor version number", Transform: transform.FromMethod("Minor")},
+			{Name: "micro", Type: proto.ColumnType_INT, Description: "Micro (patch) version number", Transform: transform.FromMethod("Micro")},
+		}),
+	}
+}
+
+// listVersion streams the version from status.php. Like nextcloud_status it
+// skips the connection test, status.php not being behind OCS.
+func listVersion(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	client, err := GetUntestedClient(ctx, d.Connection)
+	if err != nil {
+		return nil, err
+	}
+	status, err := fetchServerStatus(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	d.StreamListItem(ctx, status)
+	return nil, nil
+}
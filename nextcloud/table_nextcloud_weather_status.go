@@ -0,0 +1,128 @@
+package nextcloud
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// weatherLocation is the location configured in the weather_status app
+type weatherLocation struct {
+	Address string          `json:"address"`
+	Lat     json.RawMessage `json:"lat"`
+	Lon     json.RawMessage `json:"lon"`
+	Mode    int             `json:"mode"`
+}
+
+// weatherForecast is an entry of the forecast time series (met.no format)
+type weatherForecast struct {
+	Time string `json:"time"`
+	Data struct {
+		Instant struct {
+			Details struct {
+				AirTemperature *float64 `json:"air_temperature"`
+			} `json:"details"`
+		} `json:"instant"`
+		Next1Hours struct {
+			Summary struct {
+				SymbolCode string `json:"symbol_code"`
+			} `json:"summary"`
+		} `json:"next_1_hours"`
+	} `json:"data"`
+}
+
+// weatherStatus is the single row of the nextcloud_weather_status table
+type weatherStatus struct {
+	Address      string
+	Lat          *float64
+	Lon          *float64
+	Mode         int
+	Temperature  *float64
+	Weather      string
+	ForecastTime time.Time
+}
+
+// tableNextcloudWeatherStatus defines the schema for the weather status of the configured user (single row)
+func tableNextcloudWeatherStatus() *plugin.Table {
+	return &plugin.Table{
+		Name:        "nextcloud_weather_status",
+		Description: "Weather location and current forecast of the configured user (weather_status app)",
+		List: &plugin.ListConfig{
+			Hydrate: listIfAppEnabled("weather_status", listWeatherStatus),
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "address", Type: proto.ColumnType_STRING, Description: "Address of the configured location", Transform: transform.FromField("Address")},
+			{Name: "lat", Type: proto.ColumnType_DOUBLE, Description: "Latitude of the location", Transform: transform.FromField("Lat")},
+			{Name: "lon", Type: proto.ColumnType_DOUBLE, Description: "Longitude of the location", Transform: transform.FromField("Lon")},
+			{Name: "mode", Type: proto.ColumnType_STRING, Description: "How the location was set (browser or address)", Transform: transform.FromField("Mode").Transform(weatherModeName)},
+			{Name: "temperature", Type: proto.ColumnType_DOUBLE, Description: "Current air temperature in degrees Celsius", Transform: transform.FromField("Temperature")},
+			{Name: "weather", Type: proto.ColumnType_STRING, Description: "Weather for the next hour (met.no symbol code, e.g. partlycloudy_day)", Transform: transform.FromField("Weather").NullIfZero()},
+			{Name: "forecast_time", Type: proto.ColumnType_TIMESTAMP, Description: "Time of the forecast entry", Transform: transform.FromField("ForecastTime").NullIfZero()},
+		}),
+	}
+}
+
+// listWeatherStatus retrieves the location and forecast of the configured user (the API is self-scoped)
+func listWeatherStatus(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	location, err := ocsGetData[weatherLocation](ctx, client, "ocs/v2.php/apps/weather_status/api/v1/location?format=json")
+	if err != nil {
+		return nil, appNotEnabledError("weather_status", err)
+	}
+	status := weatherStatus{
+		Address: location.Address,
+		Lat:     coordinateFloat(location.Lat),
+		Lon:     coordinateFloat(location.Lon),
+		Mode:    location.Mode,
+	}
+
+	// the forecast is only available once a location is set
+	if status.Lat != nil && status.Lon != nil {
+		forecast, err := ocsGet[weatherForecast](ctx, client, "ocs/v2.php/apps/weather_status/api/v1/forecast?format=json")
+		if err != nil {
+			plugin.Logger(ctx).Warn("listWeatherStatus", "forecast_error", err)
+		} else if len(forecast) > 0 {
+			status.Temperature = forecast[0].Data.Instant.Details.AirTemperature
+			status.Weather = forecast[0].Data.Next1Hours.Summary.SymbolCode
+			status.ForecastTime, _ = time.Parse(time.RFC3339, forecast[0].Time)
+		}
+	}
+
+	d.StreamListItem(ctx, status)
+	return nil, nil
+}
+
+// coordinateFloat converts a coordinate, sent as a number or a string (""
+// when no location is set), into a float; nil if unset
+func coordinateFloat(raw json.RawMessage) *float64 {
+	value := strings.Trim(strings.TrimSpace(string(raw)), `"`)
+	if value == "" || value == "null" {
+		return nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil
+	}
+	return &f
+}
+
+// weatherModeName maps the numeric location mode to a readable name
+func weatherModeName(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	switch d.Value {
+	case 1:
+		return "browser", nil
+	case 2:
+		return "address", nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,126 @@
+package nextcloud
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// workflowScopes are the rule sets of the Flow API: rules applying to the
+// whole server (admins only) and rules of the configured user
+var workflowScopes = []string{"global", "user"}
+
+// workflowRule is a Flow rule (workflowengine app)
+type workflowRule struct {
+	ID        int64           `json:"id"`
+	Class     string          `json:"class"`
+	Name      string          `json:"name"`
+	Checks    json.RawMessage `json:"checks"`
+	Operation string          `json:"operation"`
+	Entity    string          `json:"entity"`
+	Events    []string        `json:"events"`
+	Scope     string          `json:"-"`
+}
+
+// OperationJSON returns the configuration of the operation, decoded when the
+// operation stores JSON (e.g. the tags of an auto-tagging rule)
+func (r workflowRule) OperationJSON() interface{} {
+	var value interface{}
+	if r.Operation == "" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(r.Operation), &value); err != nil {
+		return r.Operation
+	}
+	return value
+}
+
+// tableNextcloudWorkflow defines the schema for the Flow rules
+func tableNextcloudWorkflow() *plugin.Table {
+	return &plugin.Table{
+		Name:        "nextcloud_workflow",
+		Description: "Flow rules (workflowengine app): global rules (admin credentials required) and rules of the configured user",
+		List: &plugin.ListConfig{
+			Hydrate: listIfAppEnabled("workflowengine", listWorkflows),
+			KeyColumns: plugin.KeyColumnSlice{
+				{Name: "scope", Require: plugin.Optional},
+			},
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "id", Type: proto.ColumnType_INT, Description: "Rule ID", Transform: transform.FromField("ID")},
+			{Name: "scope", Type: proto.ColumnType_STRING, Description: "Rule set the rule belongs to (global or user)", Transform: transform.FromField("Scope")},
+			{Name: "class", Type: proto.ColumnType_STRING, Description: "Class of the operation run by the rule (e.g. OCA\\FilesAccessControl\\Operation)", Transform: transform.FromField("Class")},
+			{Name: "name", Type: proto.ColumnType_STRING, Description: "Name of the rule", Transform: transform.FromField("Name").NullIfZero()},
+			{Name: "checks", Type: proto.ColumnType_JSON, Description: "Conditions of the rule (class, operator and value of each check)", Transform: transform.FromField("Checks")},
+			{Name: "operation", Type: proto.ColumnType_JSON, Description: "Configuration of the operation", Transform: transform.FromMethod("OperationJSON")},
+			{Name: "entity", Type: proto.ColumnType_STRING, Description: "Class of the entity the rule applies to (e.g. OCA\\WorkflowEngine\\Entity\\File)", Transform: transform.FromField("Entity")},
+			{Name: "events", Type: proto.ColumnType_JSON, Description: "Events triggering the rule", Transform: transform.FromField("Events")},
+		}),
+	}
+}
+
+// listWorkflows streams the rules of every scope, or of the requested one. A
+// scope the configured user may not read is skipped unless it was requested.
+func listWorkflows(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := workflowScopes
+	requested := ""
+	if qual := d.EqualsQuals["scope"]; qual != nil {
+		requested = qual.GetStringValue()
+		scopes = []string{requested}
+	}
+
+	for _, scope := range scopes {
+		rules, err := listWorkflowRules(ctx, client, scope)
+		if err != nil {
+			if requested == "" && workflowScopeForbidden(err) {
+				plugin.Logger(ctx).Warn("listWorkflows", "scope", scope, "message", "rule set not readable by the configured user, skipping", "error", err)
+				continue
+			}
+			return nil, err
+		}
+		for _, rule := range rules {
+			d.StreamListItem(ctx, rule)
+			if d.RowsRemaining(ctx) == 0 {
+				return nil, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// listWorkflowRules fetches the rules of a scope, grouped by operation class in the response
+func listWorkflowRules(ctx context.Context, client *NextcloudClient, scope string) ([]workflowRule, error) {
+	endpoint := fmt.Sprintf("ocs/v2.php/apps/workflowengine/api/v1/workflows/%s?format=json", scope)
+	byClass, err := ocsGetData[map[string][]workflowRule](ctx, client, endpoint)
+	if err != nil {
+		return nil, appNotEnabledError("workflowengine", err)
+	}
+
+	var rules []workflowRule
+	for _, classRules := range byClass {
+		for _, rule := range classRules {
+			rule.Scope = scope
+			rules = append(rules, rule)
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+	return rules, nil
+}
+
+// workflowScopeForbidden reports whether err means the rule set is not readable
+// by the configured user (global rules for a non-admin, user rules when user
+// flows are disabled)
+func workflowScopeForbidden(err error) bool {
+	return errors.Is(err, ErrForbidden)
+}
@@ -0,0 +1,182 @@
+package nextcloud
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+)
+
+var (
+	// baseTransport is the clone of http.DefaultTransport every client transport derives from
+	baseTransport     *http.Transport
+	baseTransportOnce sync.Once
+
+	// transports holds one transport per distinct proxy/TLS configuration so
+	// that repeated queries reuse pooled TCP/TLS connections
+	transports   = map[string]*http.Transport{}
+	transportsMu sync.Mutex
+)
+
+// sharedBaseTransport returns the package-wide base transport. It is a clone of
+// http.DefaultTransport so the standard HTTP_PROXY/HTTPS_PROXY/NO_PROXY
+// environment variables keep working.
+func sharedBaseTransport() *http.Transport {
+	baseTransportOnce.Do(func() {
+		baseTransport = http.DefaultTransport.(*http.Transport).Clone()
+		baseTransport.Proxy = http.ProxyFromEnvironment
+	})
+	return baseTransport
+}
+
+// newTransport returns the HTTP transport for a connection configuration.
+// Connections without proxy or TLS options share the base transport; the
+// others get one transport per distinct set of options, built once.
+func newTransport(cfg *NextcloudConfig) (*http.Transport, error) {
+	key := transportKey(cfg)
+	if key == "" {
+		return sharedBaseTransport(), nil
+	}
+
+	minVersion, err := parseTLSMinVersion(cfg.TLSMinVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	transportsMu.Lock()
+	defer transportsMu.Unlock()
+	if transport, ok := transports[key]; ok {
+		return transport, nil
+	}
+
+	transport := sharedBaseTransport().Clone()
+
+	// An explicit proxy_url takes precedence over the environment
+	if cfg.ProxyURL != nil && *cfg.ProxyURL != "" {
+		u, err := parseProxyURL(*cfg.ProxyURL)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(u)
+	}
+
+	// Client certificate for deployments requiring mTLS
+	cert, err := loadClientCertificate(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cert != nil {
+		tlsConfig(transport).Certificates = []tls.Certificate{*cert}
+	}
+
+	// Hardened deployments may refuse anything older than TLS 1.3
+	if minVersion != 0 {
+		tlsConfig(transport).MinVersion = minVersion
+	}
+
+	transports[key] = transport
+	return transport, nil
+}
+
+// transportKey identifies the transport options of cfg; empty when none are set
+func transportKey(cfg *NextcloudConfig) string {
+	value := func(v *string) string {
+		if v == nil {
+			return ""
+		}
+		return strings.TrimSpace(*v)
+	}
+	parts := []string{value(cfg.ProxyURL), value(cfg.ClientCert), value(cfg.ClientKey), value(cfg.TLSMinVersion)}
+	if strings.Join(parts, "") == "" {
+		return ""
+	}
+	return strings.Join(parts, "\x00")
+}
+
+// tlsMinVersions are the accepted values of tls_min_version
+var tlsMinVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// parseTLSMinVersion validates tls_min_version. Returns 0 when it is not set,
+// leaving Go's default minimum.
+func parseTLSMinVersion(value *string) (uint16, error) {
+	if value == nil || strings.TrimSpace(*value) == "" {
+		return 0, nil
+	}
+	version, ok := tlsMinVersions[strings.TrimSpace(*value)]
+	if !ok {
+		return 0, fmt.Errorf("invalid tls_min_version %q: must be \"1.2\" or \"1.3\"", *value)
+	}
+	return version, nil
+}
+
+// tlsConfig returns the TLS configuration of transport, creating it if needed
+func tlsConfig(transport *http.Transport) *tls.Config {
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{}
+	}
+	return transport.TLSClientConfig
+}
+
+// loadClientCertificate loads client_cert/client_key, given either as PEM file
+// paths or inline PEM blocks. Returns nil when neither is configured.
+func loadClientCertificate(cfg *NextcloudConfig) (*tls.Certificate, error) {
+	certValue := ""
+	if cfg.ClientCert != nil {
+		certValue = strings.TrimSpace(*cfg.ClientCert)
+	}
+	keyValue := ""
+	if cfg.ClientKey != nil {
+		keyValue = strings.TrimSpace(*cfg.ClientKey)
+	}
+	if certValue == "" && keyValue == "" {
+		return nil, nil
+	}
+	if certValue == "" || keyValue == "" {
+		return nil, fmt.Errorf("client_cert and client_key must be configured together")
+	}
+
+	certPEM, err := readPEM(certValue)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read client_cert: %w", err)
+	}
+	keyPEM, err := readPEM(keyValue)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read client_key: %w", err)
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("invalid client certificate (does client_key match client_cert?): %w", err)
+	}
+	return &cert, nil
+}
+
+// readPEM returns value itself when it is an inline PEM block, otherwise the content of the file it points to
+func readPEM(value string) ([]byte, error) {
+	if strings.HasPrefix(value, "-----BEGIN") {
+		return []byte(value), nil
+	}
+	return os.ReadFile(value)
+}
+
+// parseProxyURL validates proxy_url (http, https or socks5)
+func parseProxyURL(proxyURL string) (*url.URL, error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy_url %q: %w", proxyURL, err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("invalid proxy_url %q: scheme must be http, https or socks5", proxyURL)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy_url %q: missing host", proxyURL)
+	}
+	return u, nil
+}
@@ -0,0 +1,61 @@
+package nextcloud
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// BenchmarkTransportDials compares the connections dialed by a client built
+// per query on the shared transport with one built on its own transport, as
+// each query creates a new client
+func BenchmarkTransportDials(b *testing.B) {
+	var dials atomic.Int64
+	server := httptest.NewUnstartedServer(rawOCSHandler(`{"id":"admin"}`))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			dials.Add(1)
+		}
+	}
+	server.Start()
+	b.Cleanup(server.Close)
+
+	serverURL, username, password, skip := server.URL, "admin", "secret", true
+	cfg := NextcloudConfig{ServerURL: &serverURL, Username: &username, Password: &password, SkipConnectionTest: &skip}
+
+	for _, bench := range []struct {
+		name   string
+		shared bool
+	}{{"shared", true}, {"per_client", false}} {
+		b.Run(bench.name, func(b *testing.B) {
+			dials.Store(0)
+			for i := 0; i < b.N; i++ {
+				client, err := NewNextcloudClient(context.Background(), &plugin.Connection{Config: cfg})
+				if err != nil {
+					b.Fatal(err)
+				}
+				var transport *http.Transport
+				if !bench.shared {
+					transport = sharedBaseTransport().Clone()
+					client.HTTPClient.Transport = transport
+				}
+				resp, err := client.MakeRequest(context.Background(), "GET", "ocs/v2.php/cloud/user?format=json", nil)
+				if err != nil {
+					b.Fatal(err)
+				}
+				_, _ = io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+				if transport != nil {
+					transport.CloseIdleConnections()
+				}
+			}
+			b.ReportMetric(float64(dials.Load())/float64(b.N), "dials/op")
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package nextcloud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// quotaUnlimited is the sentinel returned by the provisioning API for users without a quota
+const quotaUnlimited = -3
+
+// ncUser represents a user from the Provisioning API
+type ncUser struct {
+	ID          string    `json:"id"`
+	DisplayName string    `json:"displayname"`
+	Email       string    `json:"email"`
+	Quota       userQuota `json:"quota"`
+	Enabled     ocsBool   `json:"enabled"`
+	LastLogin   int64     `json:"lastLogin"`
+
+	// Absent from the responses of older servers
+	Backend         string `json:"backend"`
+	StorageLocation string `json:"storageLocation"`
+	Language        string `json:"language"`
+	Locale          string `json:"locale"`
+
+	// fetched is set once the details have been retrieved
+	fetched bool
+}
+
+// userQuota holds the storage consumption of a user. The API returns the
+// quota either as a number of bytes (or -3 for unlimited) or, on older
+// servers, as a string such as "none", so it is kept raw and normalized by Limit.
+type userQuota struct {
+	Free     float64         `json:"free"`
+	Used     float64         `json:"used"`
+	Total    float64         `json:"total"`
+	Relative float64         `json:"relative"`
+	Quota    json.RawMessage `json:"quota"`
+}
+
+// Limit returns the quota in bytes, or unlimited=true when no quota applies
+func (q userQuota) Limit() (bytes int64, unlimited bool) {
+	if len(q.Quota) == 0 {
+		return 0, true
+	}
+	var n float64
+	if err := json.Unmarshal(q.Quota, &n); err == nil {
+		if int64(n) == quotaUnlimited {
+			return 0, true
+		}
+		// other negative values mean the quota has not been computed yet
+		if n < 0 {
+			return 0, true
+		}
+		return int64(n), false
+	}
+	var s string
+	if err := json.Unmarshal(q.Quota, &s); err == nil {
+		if v, err := strconv.ParseInt(s, 10, 64); err == nil && v >= 0 {
+			return v, false
+		}
+	}
+	// "none", "default", -3 and friends all mean no explicit limit
+	return 0, true
+}
+
+// listUserIDs returns the IDs of every user visible to the configured account
+func listUserIDs(ctx context.Context, client *NextcloudClient) ([]string, error) {
+	data, err := ocsGetData[struct {
+		Users []string `json:"users"`
+	}](ctx, client, "ocs/v1.php/cloud/users?format=json")
+	if err != nil {
+		return nil, err
+	}
+	return data.Users, nil
+}
+
+// fetchUser retrieves the details of a single user
+func fetchUser(ctx context.Context, client *NextcloudClient, userID string) (*ncUser, error) {
+	endpoint := fmt.Sprintf("ocs/v1.php/cloud/users/%s?format=json", url.PathEscape(userID))
+	user, err := ocsGetData[ncUser](ctx, client, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get user %s: %w", userID, err)
+	}
+	if user.ID == "" {
+		user.ID = userID
+	}
+	user.fetched = true
+	return &user, nil
+}
+
+// listUsersHydrate streams a stub ncUser per user, or only the one matching a
+// "user_id = X" qualifier. Details are filled in by getUserHydrate.
+func listUsersHydrate(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	if qual := d.EqualsQuals["user_id"]; qual != nil {
+		d.StreamListItem(ctx, &ncUser{ID: qual.GetStringValue()})
+		return nil, nil
+	}
+
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := listUserIDs(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		d.StreamListItem(ctx, &ncUser{ID: id})
+		if d.RowsRemaining(ctx) == 0 {
+			return nil, nil
+		}
+	}
+	return nil, nil
+}
+
+// listUsersDetailedHydrate streams every user (or the one of a "user_id = X"
+// qualifier) with its details, see forEachUser.
+func listUsersDetailedHydrate(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	return nil, forEachUser(ctx, d, func(user *ncUser) bool {
+		d.StreamListItem(ctx, user)
+		return d.RowsRemaining(ctx) != 0
+	})
+}
+
+// forEachUser calls fn for every user, or the one of a "user_id = X"
+// qualifier, until it returns false. The details of the users are fetched by
+// a bounded pool of max_concurrency workers, and a user whose details cannot
+// be fetched is logged and skipped. When the query selects user_id only, the
+// per-user requests are skipped and fn gets stubs.
+func forEachUser(ctx context.Context, d *plugin.QueryData, fn func(*ncUser) bool) error {
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return err
+	}
+
+	var ids []string
+	if qual := d.EqualsQuals["user_id"]; qual != nil {
+		ids = []string{qual.GetStringValue()}
+	} else if ids, err = listUserIDs(ctx, client); err != nil {
+		return err
+	}
+
+	if onlyColumnsRequested(d, "user_id") {
+		for _, id := range ids {
+			if !fn(&ncUser{ID: id}) {
+				break
+			}
+		}
+		return nil
+	}
+	forEachUserDetailed(ctx, client, ids, maxConcurrency(d.Connection), fn)
+	return nil
+}
+
+// forEachUserDetailed fetches the details of the users ids with a bounded pool
+// of concurrency workers and calls fn for each of them, in completion order,
+// from a single goroutine. A user whose details cannot be fetched is logged
+// and skipped. The remaining requests are cancelled when fn returns false.
+func forEachUserDetailed(ctx context.Context, client *NextcloudClient, ids []string, concurrency int, fn func(*ncUser) bool) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan string)
+	results := make(chan *ncUser)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range jobs {
+				user, err := fetchUser(ctx, client, id)
+				if err != nil {
+					if ctx.Err() == nil {
+						plugin.Logger(ctx).Warn("forEachUserDetailed", "user_id", id, "error", err)
+					}
+					continue
+				}
+				select {
+				case results <- user:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		for _, id := range ids {
+			select {
+			case jobs <- id:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// users are passed to fn in completion order
+	for user := range results {
+		if ctx.Err() != nil {
+			continue
+		}
+		if !fn(user) {
+			cancel()
+		}
+	}
+}
+
+// getUserHydrate fetches the full details of the user streamed by listUsersHydrate
+func getUserHydrate(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	var userID string
+	if user, ok := h.Item.(*ncUser); ok {
+		if user.fetched {
+			return user, nil
+		}
+		userID = user.ID
+	} else if qual := d.EqualsQuals["user_id"]; qual != nil {
+		userID = qual.GetStringValue()
+	}
+	if userID == "" {
+		return nil, fmt.Errorf("user_id not provided")
+	}
+
+	client, err := GetClient(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	return fetchUser(ctx, client, userID)
+}
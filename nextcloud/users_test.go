@@ -0,0 +1,91 @@
+package nextcloud
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// TestForEachUserSkipsDetails checks that the details of the users, one
+// request each, are only fetched when a column other than user_id is selected
+func TestForEachUserSkipsDetails(t *testing.T) {
+	tests := []struct {
+		columns     []string
+		wantDetails bool
+	}{
+		{[]string{"user_id"}, false},
+		{[]string{"user_id", "_ctx"}, false},
+		{[]string{"user_id", "email"}, true},
+		{[]string{"display_name"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.columns, ","), func(t *testing.T) {
+			var detailRequests atomic.Int32
+			_, conn := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/ocs/v1.php/cloud/users" {
+					writeOCS(t, w, map[string][]string{"users": {"alice", "bob"}})
+					return
+				}
+				detailRequests.Add(1)
+				writeOCS(t, w, map[string]string{"id": strings.TrimPrefix(r.URL.Path, "/ocs/v1.php/cloud/users/")})
+			}))
+			d := &plugin.QueryData{Connection: conn, QueryContext: &plugin.QueryContext{Columns: tt.columns}}
+
+			var ids []string
+			err := forEachUser(context.Background(), d, func(user *ncUser) bool {
+				if user.fetched != tt.wantDetails {
+					t.Errorf("user %s: fetched = %v, want %v", user.ID, user.fetched, tt.wantDetails)
+				}
+				ids = append(ids, user.ID)
+				return true
+			})
+			if err != nil {
+				t.Fatalf("forEachUser: %v", err)
+			}
+			slices.Sort(ids)
+			if !slices.Equal(ids, []string{"alice", "bob"}) {
+				t.Errorf("got users %v, want [alice bob]", ids)
+			}
+			if got := detailRequests.Load() > 0; got != tt.wantDetails {
+				t.Errorf("cloud/users/{id} requested = %v (%d requests), want %v", got, detailRequests.Load(), tt.wantDetails)
+			}
+		})
+	}
+}
+
+// BenchmarkForEachUserDetailed compares fetching the details of 50 users one
+// at a time and with the default pool of workers, against a server taking 2ms
+// per user
+func BenchmarkForEachUserDetailed(b *testing.B) {
+	client := newTestClient(b, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(2 * time.Millisecond)
+		writeOCS(b, w, map[string]string{"id": strings.TrimPrefix(r.URL.Path, "/ocs/v1.php/cloud/users/")})
+	}))
+	ids := make([]string, 50)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("user%d", i)
+	}
+
+	for _, concurrency := range []int{1, defaultMaxConcurrency} {
+		b.Run(fmt.Sprintf("concurrency=%d", concurrency), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				count := 0
+				forEachUserDetailed(context.Background(), client, ids, concurrency, func(*ncUser) bool {
+					count++
+					return true
+				})
+				if count != len(ids) {
+					b.Fatalf("got %d users, want %d", count, len(ids))
+				}
+			}
+		})
+	}
+}
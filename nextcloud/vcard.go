@@ -0,0 +1,106 @@
+package nextcloud
+
+import (
+	"strings"
+)
+
+// vcardProperty is a single content line of a vCard, e.g. EMAIL;TYPE=work:a@b.c
+type vcardProperty struct {
+	Name   string
+	Params map[string][]string
+	Value  string
+}
+
+// vcard holds the properties of a vCard (3.0 or 4.0) by upper-case name
+type vcard map[string][]vcardProperty
+
+// First returns the value of the first property called name, or ""
+func (c vcard) First(name string) string {
+	if props := c[name]; len(props) > 0 {
+		return props[0].Value
+	}
+	return ""
+}
+
+// parseVCard parses the first BEGIN:VCARD...END:VCARD block of data. It only
+// handles what the contact table needs: line unfolding, group prefixes
+// (item1.EMAIL), parameters and the \n \, \; escapes.
+func parseVCard(data string) vcard {
+	card := vcard{}
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	// unfold continuation lines (starting with a space or a tab)
+	data = strings.NewReplacer("\n ", "", "\n\t", "").Replace(data)
+
+	for _, line := range strings.Split(data, "\n") {
+		colon := vcardValueIndex(line)
+		if colon < 0 {
+			continue
+		}
+		head, value := line[:colon], line[colon+1:]
+
+		parts := strings.Split(head, ";")
+		name := strings.ToUpper(parts[0])
+		if i := strings.LastIndex(name, "."); i >= 0 {
+			name = name[i+1:]
+		}
+		if name == "BEGIN" || name == "VERSION" {
+			continue
+		}
+		if name == "END" {
+			break
+		}
+
+		prop := vcardProperty{Name: name, Params: map[string][]string{}, Value: value}
+		for _, param := range parts[1:] {
+			key, values, ok := strings.Cut(param, "=")
+			if !ok {
+				// vCard 2.1/3.0 shorthand, e.g. EMAIL;WORK:...
+				key, values = "TYPE", param
+			}
+			key = strings.ToUpper(key)
+			for _, v := range strings.Split(values, ",") {
+				prop.Params[key] = append(prop.Params[key], strings.ToLower(strings.Trim(v, `"`)))
+			}
+		}
+		card[name] = append(card[name], prop)
+	}
+	return card
+}
+
+// vcardValueIndex returns the index of the colon separating the property name
+// and parameters from the value, skipping colons inside quoted parameters
+func vcardValueIndex(line string) int {
+	quoted := false
+	for i, r := range line {
+		switch r {
+		case '"':
+			quoted = !quoted
+		case ':':
+			if !quoted {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// vcardUnescape decodes the \n, \, \; and \\ escapes of a text value
+func vcardUnescape(value string) string {
+	return strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(value)
+}
+
+// vcardSplit splits a structured or list value on sep, ignoring escaped separators
+func vcardSplit(value string, sep byte) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case sep:
+			parts = append(parts, vcardUnescape(value[start:i]))
+			start = i + 1
+		}
+	}
+	return append(parts, vcardUnescape(value[start:]))
+}
@@ -0,0 +1,103 @@
+package nextcloud
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseVCard(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want vcard
+	}{
+		{
+			name: "simple",
+			data: "BEGIN:VCARD\r\nVERSION:3.0\r\nFN:Alice Martin\r\nEND:VCARD\r\n",
+			want: vcard{"FN": {{Name: "FN", Params: map[string][]string{}, Value: "Alice Martin"}}},
+		},
+		{
+			name: "folded lines",
+			data: "BEGIN:VCARD\r\nVERSION:4.0\r\nNOTE:first line\r\n  and a space\r\n\tthen a tab\r\nFN:Alice\r\nEND:VCARD\r\n",
+			want: vcard{
+				"NOTE": {{Name: "NOTE", Params: map[string][]string{}, Value: "first line and a spacethen a tab"}},
+				"FN":   {{Name: "FN", Params: map[string][]string{}, Value: "Alice"}},
+			},
+		},
+		{
+			name: "type parameters",
+			data: "BEGIN:VCARD\nVERSION:3.0\nEMAIL;TYPE=WORK,pref:alice@example.com\nitem1.TEL;type=\"cell\";VALUE=uri:tel:+33-6\nEMAIL;HOME:alice@home.example\nEND:VCARD\n",
+			want: vcard{
+				"EMAIL": {
+					{Name: "EMAIL", Params: map[string][]string{"TYPE": {"work", "pref"}}, Value: "alice@example.com"},
+					{Name: "EMAIL", Params: map[string][]string{"TYPE": {"home"}}, Value: "alice@home.example"},
+				},
+				"TEL": {{Name: "TEL", Params: map[string][]string{"TYPE": {"cell"}, "VALUE": {"uri"}}, Value: "tel:+33-6"}},
+			},
+		},
+		{
+			name: "escapes kept raw",
+			data: "BEGIN:VCARD\nVERSION:3.0\nNOTE:one\\, two\\nthree\nEND:VCARD\n",
+			want: vcard{"NOTE": {{Name: "NOTE", Params: map[string][]string{}, Value: `one\, two\nthree`}}},
+		},
+		{
+			name: "missing END:VCARD",
+			data: "BEGIN:VCARD\nVERSION:3.0\nFN:Alice\nEMAIL:alice@example.com",
+			want: vcard{
+				"FN":    {{Name: "FN", Params: map[string][]string{}, Value: "Alice"}},
+				"EMAIL": {{Name: "EMAIL", Params: map[string][]string{}, Value: "alice@example.com"}},
+			},
+		},
+		{
+			name: "first card only",
+			data: "BEGIN:VCARD\nFN:Alice\nEND:VCARD\nBEGIN:VCARD\nFN:Bob\nEND:VCARD\n",
+			want: vcard{"FN": {{Name: "FN", Params: map[string][]string{}, Value: "Alice"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseVCard(tt.data); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseVCard() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVCardUnescape(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{`plain`, "plain"},
+		{`one\, two`, "one, two"},
+		{`first\nsecond\Nthird`, "first\nsecond\nthird"},
+		{`a\;b`, "a;b"},
+		{`back\\slash`, `back\slash`},
+	}
+
+	for _, tt := range tests {
+		if got := vcardUnescape(tt.value); got != tt.want {
+			t.Errorf("vcardUnescape(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestVCardSplit(t *testing.T) {
+	tests := []struct {
+		value string
+		sep   byte
+		want  []string
+	}{
+		{`Martin;Alice;;Dr;`, ';', []string{"Martin", "Alice", "", "Dr", ""}},
+		{`Rue de la Paix\, 2;Paris`, ';', []string{"Rue de la Paix, 2", "Paris"}},
+		{`a\;b;c`, ';', []string{"a;b", "c"}},
+		{`work,home\,office`, ',', []string{"work", "home,office"}},
+	}
+
+	for _, tt := range tests {
+		if got := vcardSplit(tt.value, tt.sep); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("vcardSplit(%q, %q) = %q, want %q", tt.value, tt.sep, got, tt.want)
+		}
+	}
+}